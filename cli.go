@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-doc-parser/internal/document"
+	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/extract"
+	"go-doc-parser/internal/parser"
+	"go-doc-parser/internal/processor"
+	"go-doc-parser/internal/report"
+	"go-doc-parser/internal/watcher"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const processUsage = `usage: go-doc-parser process [flags] <report | reports.zip | directory>...
+
+Processes the given reports without starting the server and prints the result.
+`
+
+// runProcess implements the "process" subcommand and returns the exit code
+func runProcess(args []string) int {
+	flags := flag.NewFlagSet("process", flag.ContinueOnError)
+
+	dictionaryPath := flags.String("dictionary", "", "path to the dictionary JSON (defaults to the DATA environment variable)")
+	cutoff := flags.Uint64("cutoff", 0, "only count events that end at or after this hour, 0 keeps all")
+	format := flags.String("format", "text", "output format: "+strings.Join(report.Formats, "|"))
+	output := flags.String("output", "", "write the result to this file instead of stdout")
+	tags := []string{}
+	flags.Func("tag", "only count events carrying this tag, may be repeated", func(value string) error {
+		tags = append(tags, value)
+		return nil
+	})
+	pivot := flags.String("pivot", "", "write event counts grouped by these comma separated fields instead: "+strings.Join(processor.PivotFields, "|")+" or a column key")
+	chart := flags.String("chart", "", "draw an SVG chart of the events by hour instead: "+strings.Join(report.ChartKinds, "|"))
+	workers := flags.Int("workers", runtime.NumCPU(), "number of files parsed at the same time")
+	passwords := passwordFlags(flags)
+	revisions := revisionsFlag(flags)
+	analysis := analysisFlags(flags)
+
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), processUsage)
+		flags.PrintDefaults()
+	}
+
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(paths) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if !slices.Contains(report.Formats, *format) {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	if *pivot != "" && !slices.Contains(report.PivotFormats, *format) {
+		fmt.Fprintf(os.Stderr, "the pivot can not be written as %s\n", *format)
+		return 2
+	}
+
+	if *chart != "" && !slices.Contains(report.ChartKinds, *chart) {
+		fmt.Fprintf(os.Stderr, "unknown chart %q\n", *chart)
+		return 2
+	}
+
+	dictionary, locations, err := readDictionary(*dictionaryPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	keyring, err := passwords()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read the keyring:", err)
+		return 1
+	}
+
+	options := processor.Options{Workers: *workers, Limits: processor.DefaultLimits, Passwords: keyring, Revisions: *revisions, Locations: locations}
+
+	err = analysis(&options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	sources, err := processor.PathSources(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to open the reports:", err)
+		return 1
+	}
+
+	process := processor.NewProcessor(dictionary, options)
+
+	data, err := process(context.Background(), sources)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to process the reports:", err)
+		return 1
+	}
+
+	result := processor.FilterTags(processor.Cutoff(data, *cutoff), tags)
+
+	for _, diagnostic := range result.Diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", diagnostic.Filename, diagnostic.Message)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to create the output:", err)
+			return 1
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	switch {
+	case *chart != "":
+		err = report.WriteChart(w, processor.NewTimeline(result), *chart)
+	case *pivot != "":
+		var table entity.Pivot
+
+		table, err = processor.NewPivot(result, processor.ParsePivotFields(*pivot))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		err = report.WritePivot(w, table, *format)
+	default:
+		err = report.Write(w, result, *format)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write the result:", err)
+		return 1
+	}
+
+	return 0
+}
+
+const watchUsage = `usage: go-doc-parser watch [flags] <directory>...
+
+Watches the directories for reports modified today and keeps
+summary.txt and report.html in the output directory up to date.
+`
+
+// runWatch implements the "watch" subcommand and returns the exit code
+func runWatch(args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+
+	dictionaryPath := flags.String("dictionary", "", "path to the dictionary JSON (defaults to the DATA environment variable)")
+	cutoff := flags.Uint64("cutoff", 0, "only count events that end at or after this hour, 0 keeps all")
+	output := flags.String("output", "report", "directory for summary.txt and report.html")
+	interval := flags.Duration("interval", 5*time.Second, "how often the directories are scanned")
+	debounce := flags.Duration("debounce", 10*time.Second, "how long a file must stay unchanged before it is processed")
+	passwords := passwordFlags(flags)
+	revisions := revisionsFlag(flags)
+	analysis := analysisFlags(flags)
+
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), watchUsage)
+		flags.PrintDefaults()
+	}
+
+	dirs, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if len(dirs) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	dictionary, locations, err := readDictionary(*dictionaryPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	keyring, err := passwords()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read the keyring:", err)
+		return 1
+	}
+
+	options := processor.Options{Limits: processor.DefaultLimits, Passwords: keyring, Revisions: *revisions, Locations: locations}
+
+	err = analysis(&options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := watcher.Watcher{
+		Dirs:      dirs,
+		Output:    *output,
+		Interval:  *interval,
+		Debounce:  *debounce,
+		Aggregate: processor.NewAggregator(dictionary, options),
+		Options:   options,
+		Cutoff:    *cutoff,
+	}
+
+	log.Printf("watching %s, writing to %s", strings.Join(dirs, ", "), *output)
+
+	err = w.Run(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// passwordFlags adds --password and --keyring, the result lists the given passwords followed by the keyring's
+func passwordFlags(flags *flag.FlagSet) func() ([]string, error) {
+	passwords := []string{}
+
+	flags.Func("password", "password of encrypted documents, may be repeated (visible to other users, prefer --keyring)", func(value string) error {
+		passwords = append(passwords, value)
+		return nil
+	})
+
+	keyring := flags.String("keyring", os.Getenv("KEYRING"), "file with passwords of encrypted documents, one per line (defaults to the KEYRING environment variable)")
+
+	return func() ([]string, error) {
+		fromKeyring, err := readKeyring(*keyring)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(passwords, fromKeyring...), nil
+	}
+}
+
+// analysisFlags adds --no-findings, --rules, --summary-template, --gazetteer and --basemap, the defaults come from the environment;
+// the result reads the files into the options
+func analysisFlags(flags *flag.FlagSet) func(options *processor.Options) error {
+	noFindings := flags.String("no-findings", os.Getenv("NO_FINDINGS"), "file with the comment phrases that mean nothing was found, one per line, /regexp/ for patterns (defaults to the NO_FINDINGS environment variable)")
+	rules := flags.String("rules", os.Getenv("RULES"), "JSON file with the rules that tag events (defaults to the RULES environment variable)")
+	gazetteer := flags.String("gazetteer", os.Getenv("GAZETTEER"), "file with the settlement names recognized in comments, one per line (defaults to the GAZETTEER environment variable)")
+	basemap := flags.String("basemap", os.Getenv("BASEMAP"), "GeoJSON file with the border and the posts drawn on the map of the report (defaults to the BASEMAP environment variable)")
+	summary := flags.String("summary-template", os.Getenv("SUMMARY_TEMPLATE"), "file with the summary template, {groups}, {flights}, {tags} and {tag:NAME} are replaced (defaults to the SUMMARY_TEMPLATE environment variable)")
+
+	return func(options *processor.Options) (err error) {
+		options.NoFindings, err = parser.LoadNoFindings(*noFindings)
+		if err != nil {
+			return fmt.Errorf("failed to read the no findings phrases: %w", err)
+		}
+
+		options.Rules, err = processor.LoadRules(*rules)
+		if err != nil {
+			return fmt.Errorf("failed to read the rules: %w", err)
+		}
+
+		options.Summary, err = processor.LoadSummaryTemplate(*summary)
+		if err != nil {
+			return fmt.Errorf("failed to read the summary template: %w", err)
+		}
+
+		options.Gazetteer, err = extract.LoadGazetteer(*gazetteer)
+		if err != nil {
+			return fmt.Errorf("failed to read the gazetteer: %w", err)
+		}
+
+		options.Basemap, err = processor.LoadBasemap(*basemap)
+		if err != nil {
+			return fmt.Errorf("failed to read the basemap: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// revisionsFlag adds --revisions, the default comes from the REVISIONS environment variable
+func revisionsFlag(flags *flag.FlagSet) *document.Revisions {
+	revisions, err := document.ParseRevisions(os.Getenv("REVISIONS"))
+	if err != nil {
+		revisions = document.AcceptRevisions
+	}
+
+	flags.Func("revisions", "how tracked changes are read: "+strings.Join(document.RevisionNames(), "|")+" (default "+revisions.String()+")", func(value string) error {
+		parsed, err := document.ParseRevisions(value)
+		revisions = parsed
+
+		return err
+	})
+
+	return &revisions
+}
+
+// parseInterspersed allows flags after the positional arguments,
+// so "process reports/*.docx --format json" works
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// readDictionary reads the dictionary from the file, or from the DATA environment variable when no path is given
+func readDictionary(path string) ([][]entity.ID, map[entity.ShortID]entity.Point, error) {
+	data := []byte(os.Getenv("DATA"))
+
+	if path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the dictionary: %w", err)
+		}
+	}
+
+	dictionary, locations, err := loadDictionary(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal the dictionary: %w", err)
+	}
+
+	return dictionary, locations, nil
+}
@@ -1,7 +1,3 @@
 module go-doc-parser
 
 go 1.24.3
-
-require github.com/fumiama/go-docx v0.0.0-20250506085032-0c30fd09304b
-
-require github.com/fumiama/imgsz v0.0.2 // indirect
@@ -0,0 +1,26 @@
+package assets
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// the upstream builds of the vendored files, static/van.js is a local stand-in until they are fetched
+//go:generate curl -fsSL -o static/van.js https://cdn.jsdelivr.net/gh/vanjs-org/van/public/van-1.5.5.nomodule.min.js
+//go:generate curl -fsSL -o van.LICENSE https://raw.githubusercontent.com/vanjs-org/van/main/LICENSE
+//go:generate curl -fsSL -o static/emblem.svg https://upload.wikimedia.org/wikipedia/commons/8/8d/Emblem_of_the_State_Border_Guard_Service_of_Ukraine.svg
+
+//go:embed template_new.gohtml static
+var files embed.FS
+
+// Template is the report page rendered by the handler
+const Template = "template_new.gohtml"
+
+// Files holds the report template and everything under static/
+var Files fs.FS = files
+
+// Static serves the frontend assets (scripts, styles, images) referenced by the template
+func Static() fs.FS {
+	static, _ := fs.Sub(files, "static")
+	return static
+}
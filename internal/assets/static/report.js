@@ -0,0 +1,1098 @@
+const { p, div, pre, button, textarea, input, span } = van.tags
+const add = van.add
+
+let data = JSON.parse(document.getElementById("data").textContent)
+
+// the night shift hours, the same as nightStart and nightEnd of the processor
+const nightStart = 18
+const nightEnd = 6
+
+for (const page of data.Pages) {
+    page.cutoff = van.state(0)
+    page.columns = van.state(false)
+    page.chart = van.state(false)
+
+    for (const groups of page.SelectedSupergroups) {
+        for (const group of groups) {
+            group.rows = () => group.Events.filter(afterCutoff(page.cutoff.val))
+            group.filtered = van.derive(() => group.rows().length)
+        }
+
+        groups.rows = () => groups.flatMap((group) => group.rows())
+
+        groups.total = van.derive(() => {
+            let total = 0
+            for (const group of groups) {
+                total += group.filtered.val
+            }
+            return total
+        })
+
+
+    }
+
+    for (const group of page.OtherGroups) {
+        group.rows = () => group.Events.filter(afterCutoff(page.cutoff.val))
+        group.filtered = van.derive(() => group.rows().length)
+    }
+}
+
+let aggregateSelected = van.derive(() => {
+    var aggregate = new Map()
+
+    for (page of data.Pages) {
+        // empty supergroups are left out like in the aggregates of the processor
+        for (groups of page.SelectedSupergroups.filter((groups) => groups.length)) {
+            var current = aggregate.get(groups[0].Name)
+            if (!current) {
+                current = { filtered: 0, events: [] }
+            }
+            current.filtered += groups.total.val
+            current.events.push(...groups.rows())
+            aggregate.set(groups[0].Name, current)
+        }
+    }
+
+    var out = []
+
+    for (var [k, v] of aggregate) {
+        const events = v.events
+        out.push({ Name: k, filtered: v.filtered, rows: () => events })
+    }
+
+    return out
+})
+
+let aggregateOther = van.derive(() => {
+    var aggregate = new Map()
+
+    for (page of data.Pages) {
+        for (group of page.OtherGroups) {
+            var key = { Type: group.Type, Name: group.Name, Hint: group.Hint }
+
+            key = JSON.stringify(key)
+
+            var current = aggregate.get(key)
+            if (!current) {
+                current = { filtered: 0, events: [] }
+            }
+
+            current.filtered += group.filtered.val
+            current.events.push(...group.rows())
+            aggregate.set(key, current)
+        }
+    }
+
+    out = []
+
+    for (var [k, v] of aggregate) {
+        k = JSON.parse(k)
+        const events = v.events
+        out.push({ ...k, filtered: v.filtered, rows: () => events })
+    }
+
+    return out
+})
+
+let aggregateComments = van.derive(() => {
+    let aggregate = new Map();
+
+    for (page of data.Pages) {
+        for (group of page.OtherGroups) {
+            for (comment of group.Events.filter(afterCutoff(page.cutoff.val)).filter((e) => e.Comment)) {
+                id = JSON.stringify({ Type: group.Type, Name: group.Name, Hint: group.Hint })
+                let current = aggregate.get(id)
+                if (!current) {
+                    current = []
+                }
+                current.push(comment)
+                aggregate.set(id, current)
+            }
+        }
+
+        for (groups of page.SelectedSupergroups) {
+            for (group of groups) {
+                for (comment of group.Events.filter(afterCutoff(page.cutoff.val)).filter((e) => e.Comment)) {
+                    id = JSON.stringify({ Name: groups[0].Name })
+                    let current = aggregate.get(id)
+                    if (!current) {
+                        current = []
+                    }
+                    current.push(comment)
+                    aggregate.set(id, current)
+                }
+            }
+        }
+    }
+
+    out = []
+
+    console.log(aggregate)
+
+    for ([k, v] of aggregate) {
+        k = JSON.parse(k)
+        out.push({ ...k, comments: v })
+    }
+
+    return out
+})
+
+// mentionLabels name the kinds of extract.Mentions
+const mentionLabels = {
+    settlement: "населений пункт",
+    plate: "номерний знак",
+    nationality: "громадянство",
+    coordinates: "координати",
+    persons: "кількість осіб",
+}
+
+// highlightComment marks the sentences the count of detained persons was read from and the entities found in the comment
+function highlightComment(event) {
+    const text = event.Comment
+    const chars = Array.from(text) // mention offsets count code points
+
+    const evidence = new Array(chars.length).fill(false)
+    const mention = new Array(chars.length).fill(-1)
+
+    let position = 0
+
+    for (const sentence of event.DetainedEvidence || []) {
+        const index = text.indexOf(sentence, position)
+        if (index < 0) {
+            continue
+        }
+
+        const start = Array.from(text.slice(0, index)).length
+        evidence.fill(true, start, start + Array.from(sentence).length)
+        position = index + sentence.length
+    }
+
+    for (const [i, m] of (event.Mentions || []).entries()) {
+        mention.fill(i, m.Start, m.End)
+    }
+
+    const out = []
+
+    // runs of characters with the same marks become one span
+    for (let start = 0; start < chars.length;) {
+        let end = start
+        while (end < chars.length && evidence[end] === evidence[start] && mention[end] === mention[start]) {
+            end++
+        }
+
+        const part = chars.slice(start, end).join("")
+        const m = mention[start] >= 0 ? event.Mentions[mention[start]] : null
+
+        const classes = [evidence[start] && "evidence", m && `mention ${m.Kind}`].filter(Boolean).join(" ")
+
+        out.push(classes ? span({ class: classes, title: m ? `${mentionLabels[m.Kind] || m.Kind}: ${m.Value}` : "" }, part) : part)
+
+        start = end
+    }
+
+    if (event.DetainedEvidence) {
+        out.push(span({ class: `detained` }, ` затриманих: ${event.Detained || 0}`))
+    }
+
+    for (const tag of event.Tags || []) {
+        out.push(" ", span({ class: `tag` }, tag))
+    }
+
+    return out
+}
+
+let renderedComments = van.derive(() => {
+    let out = div({ class: "matrix" });
+
+    for (group of aggregateComments.val) {
+        var t = div({ class: `table` },
+            div({ class: `divider` },
+                [group.Type, group.Name, group.Hint].filter(Boolean).join(" ")
+            ),
+        )
+
+        if (!group.comments) {
+            continue
+        }
+
+        for (comment of group.comments) {
+            add(t, div({ style: `grid-column: span 4;` }, highlightComment(comment)))
+        }
+
+        add(out, t)
+    }
+
+    return out
+})
+
+// detainedText mirrors the Go summary: the "_" placeholder stays for the comments without a count
+function detainedText(comments) {
+    let detained = 0
+    let unknown = 0
+
+    for (const comment of comments) {
+        if (comment.DetainedEvidence) {
+            detained += comment.Detained || 0
+        } else {
+            unknown++
+        }
+    }
+
+    if (unknown === 0) {
+        return `${detained}`
+    }
+
+    if (detained > 0) {
+        return `${detained} + _`
+    }
+
+    return "_"
+}
+
+function Plural(one, few, many) {
+    return function (n) {
+        const mod10 = n % 10;
+        const mod100 = n % 100;
+
+        let out = many;
+
+        if (mod10 === 1 && mod100 !== 11) {
+            out = one;
+        } else if (mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14)) {
+            out = few;
+        }
+
+        return `${n} ${out}`;
+    };
+}
+
+
+let summary = van.derive(() => {
+    let out = ""
+
+    let cases = new Map(aggregateComments.val.map(comment => [comment.Name, comment.comments]))
+
+    for ([index, groups] of aggregateSelected.val.entries()) {
+        let subsummary = "ОПДК не виявлено"
+        let comments = cases.get(groups.Name) || []
+        let count = comments.length
+
+        let casesText = Plural("випадку", "випадках", "випадках")
+        if (count > 0) {
+            subsummary = `в ${casesText(count)} ${detainedText(comments)} затриманих`
+        }
+        out += `${index + 1}. ${groups.Name} - польотів: ${groups.filtered}, ${subsummary};\n`
+    }
+
+    let flights = 0
+    let tags = new Map()
+
+    for (const group of aggregateSelected.val) {
+        flights += group.filtered
+    }
+
+    for (const group of [...aggregateSelected.val, ...aggregateOther.val]) {
+        for (const event of group.rows()) {
+            for (const tag of event.Tags || []) {
+                tags.set(tag, (tags.get(tag) || 0) + 1)
+            }
+        }
+    }
+
+    // the placeholders of processor.Summary
+    return (data.SummaryTemplate || "{groups}").replace(/\{(groups|flights|tags|tag:[^{}]+)\}/g, (match, name) => {
+        switch (name) {
+            case "groups": return out
+            case "flights": return `${flights}`
+            case "tags": return [...tags.keys()].sort().map((tag) => `${tag}: ${tags.get(tag)};\n`).join("")
+        }
+
+        return `${tags.get(name.slice("tag:".length)) || 0}`
+    })
+})
+
+// pivotFields mirror processor.PivotFields, the captured columns of the pages come after them
+const pivotFields = [
+    { key: "type", label: "Тип" },
+    { key: "name", label: "Назва" },
+    { key: "hint", label: "Уточнення" },
+    { key: "supergroup", label: "Група" },
+    { key: "hour", label: "Година" },
+    { key: "shift", label: "Зміна" },
+    { key: "file", label: "Файл" },
+    { key: "folder", label: "Папка" },
+    { key: "tag", label: "Тег" },
+]
+
+for (const page of data.Pages) {
+    for (const column of page.Columns || []) {
+        if (!pivotFields.some((field) => field.key === column)) {
+            pivotFields.push({ key: column, label: column })
+        }
+    }
+}
+
+let pivotBy = van.state([])
+
+function pivotValue(field, page, group, supergroup, event) {
+    switch (field) {
+        case "type": return group.Type
+        case "name": return group.Name
+        case "hint": return group.Hint
+        case "supergroup": return supergroup
+        case "hour": return String(event.End).padStart(2, "0")
+        case "shift": return event.End >= nightStart || event.End < nightEnd ? "ніч" : "день"
+        case "file": return page.Filename
+        case "folder": return page.Folder
+        case "tag": return event.Tags && event.Tags.length ? event.Tags : [""]
+    }
+
+    return (event.Attributes || {})[field] || ""
+}
+
+// pivot groups the events left after the page cutoffs like processor.NewPivot does
+let pivot = van.derive(() => {
+    const by = pivotBy.val
+    const rows = new Map()
+
+    const collect = (page, group, supergroup) => {
+        for (const event of group.Events.filter(afterCutoff(page.cutoff.val))) {
+            // an event with several tags is counted once for each of them
+            let combinations = [[]]
+
+            for (const field of by) {
+                const values = [pivotValue(field, page, group, supergroup, event)].flat()
+                combinations = combinations.flatMap((combination) => values.map((value) => [...combination, value]))
+            }
+
+            for (const values of combinations) {
+                const key = JSON.stringify(values)
+
+                let row = rows.get(key)
+                if (!row) {
+                    row = { values: values, count: 0, duration: 0, first: event.End, last: event.End }
+                    rows.set(key, row)
+                }
+
+                row.count++
+                row.duration += event.Duration || 0
+                row.first = Math.min(row.first, event.End)
+                row.last = Math.max(row.last, event.End)
+            }
+        }
+    }
+
+    for (const page of data.Pages) {
+        for (const groups of page.SelectedSupergroups) {
+            for (const group of groups) {
+                collect(page, group, groups[0].Name)
+            }
+        }
+
+        for (const group of page.OtherGroups) {
+            collect(page, group, "")
+        }
+    }
+
+    const out = [...rows.values()]
+    out.sort((a, b) => {
+        for (let i = 0; i < a.values.length; i++) {
+            if (a.values[i] !== b.values[i]) {
+                return a.values[i] < b.values[i] ? -1 : 1
+            }
+        }
+        return 0
+    })
+
+    return out
+})
+
+function renderPivot() {
+    const by = pivotBy.val
+    const labels = by.map((key) => pivotFields.find((field) => field.key === key).label)
+
+    var out = div({ class: `records`, style: `grid-template-columns: repeat(${by.length + 3}, auto);` })
+
+    for (const label of [...labels, "Кількість", "Тривалість, хв", "Години"]) {
+        add(out, div({ class: `column` }, label))
+    }
+
+    for (const row of pivot.val) {
+        for (const value of row.values) {
+            add(out, div(value))
+        }
+
+        add(out,
+            div({ class: `number` }, row.count),
+            div({ class: `number` }, row.duration),
+            div({ class: `number` }, row.first === row.last ? row.first : `${row.first}-${row.last}`),
+        )
+    }
+
+    return out
+}
+
+let showDropped = van.state(false)
+
+// renderDropped lists the comment paragraphs left out as saying nothing was found, with the rows they came from
+function renderDropped() {
+    const events = []
+
+    for (const page of data.Pages) {
+        for (const groups of page.SelectedSupergroups) {
+            for (const group of groups) {
+                events.push(...group.Events.filter((e) => e.Dropped))
+            }
+        }
+
+        for (const group of page.OtherGroups) {
+            events.push(...group.Events.filter((e) => e.Dropped))
+        }
+    }
+
+    var out = div({ class: `options`, style: `gap: 4px` },
+        input({ type: "checkbox", onchange: (e) => showDropped.val = e.target.checked }),
+        p(`Відкинуті як «не виявлено»: ${events.length}`),
+    )
+
+    if (!showDropped.val) {
+        return out
+    }
+
+    var list = div({ class: `records`, style: `grid-template-columns: auto auto;` })
+
+    for (const event of events) {
+        const source = event.Provenance || {}
+
+        add(list, div({ class: `source` }, `${source.File || ""}, рядок ${source.Row || "?"}`), div(event.Dropped.join("\n")))
+    }
+
+    return div(out, list)
+}
+
+let explain = van.state(false)
+let explainOther = van.state(false)
+
+// renderExplanations shows how the name cell of every row was read and matched against the dictionary
+function renderExplanations() {
+    if (!explain.val) {
+        return div()
+    }
+
+    const explanations = (data.Explanations || []).filter((e) => !explainOther.val || !e.Supergroup)
+
+    var out = div({ class: `records`, style: `grid-template-columns: repeat(6, auto);` })
+
+    for (const label of ["Рядок", "Клітинка", "Префікси", "Розпізнано", "Група", "Найближчі"]) {
+        add(out, div({ class: `column` }, label))
+    }
+
+    for (const e of explanations) {
+        add(out,
+            div(`${e.File}, ${e.Row}`),
+            div(e.Cell),
+            div((e.Tokens || []).join(" + ")),
+            div([e.ID.Type, e.ID.Name, e.ID.Hint].filter(Boolean).join(" | ")),
+            div(e.Supergroup || "Інші"),
+            div((e.Nearest || []).map((c) => `${[c.Type, c.Name, c.Hint].filter(Boolean).join(" ")} (${c.Distance})`).join("\n")),
+        )
+    }
+
+    return out
+}
+
+const svg = van.tags("http://www.w3.org/2000/svg")
+
+// supergroups are colored in the dictionary order, the other posts are grey
+const mapColors = ["#2a7ab0", "#b0582a", "#7a2ab0", "#2ab05a", "#b02a4a", "#b0a02a", "#2ab0a8", "#5a2ab0"]
+
+function mapColor(index) {
+    return index < 0 ? "#888" : mapColors[index % mapColors.length]
+}
+
+// basemapShapes flattens the GeoJSON of the basemap into lines and named points
+function basemapShapes(geojson) {
+    const lines = []
+    const points = []
+
+    const visit = (geometry, name) => {
+        if (!geometry) {
+            return
+        }
+
+        const c = geometry.coordinates
+
+        switch (geometry.type) {
+            case "Point":
+                points.push({ coordinates: c, name })
+                break
+            case "MultiPoint":
+                points.push(...c.map((coordinates) => ({ coordinates, name })))
+                break
+            case "LineString":
+                lines.push(c)
+                break
+            case "MultiLineString":
+            case "Polygon":
+                lines.push(...c)
+                break
+            case "MultiPolygon":
+                c.forEach((polygon) => lines.push(...polygon))
+                break
+            case "GeometryCollection":
+                geometry.geometries.forEach((g) => visit(g, name))
+                break
+        }
+    }
+
+    const walk = (object) => {
+        switch (object.type) {
+            case "FeatureCollection":
+                object.features.forEach(walk)
+                break
+            case "Feature":
+                visit(object.geometry, (object.properties || {}).name || "")
+                break
+            default:
+                visit(object, "")
+        }
+    }
+
+    geojson && walk(geojson)
+
+    return { lines, points }
+}
+
+const basemap = basemapShapes(data.Basemap)
+
+// mapMarkers places the events left after the page cutoffs like the GeoJSON export does:
+// at the first coordinates written in the row, otherwise at the location of the post
+let mapMarkers = van.derive(() => {
+    const markers = new Map()
+
+    const put = (group, supergroup, index) => {
+        for (const event of group.rows()) {
+            const point = (event.Positions || [])[0] || group.Location
+            if (!point) {
+                continue
+            }
+
+            const key = `${point.Lat},${point.Lon},${index}`
+            const marker = markers.get(key) || { point, supergroup, index, titles: new Set(), events: [] }
+            marker.titles.add([group.Type, group.Name, group.Hint].filter(Boolean).join(" "))
+            marker.events.push(event)
+            markers.set(key, marker)
+        }
+    }
+
+    for (const page of data.Pages) {
+        page.SelectedSupergroups.forEach((groups, index) => {
+            for (const group of groups) {
+                put(group, groups[0].Name, index)
+            }
+        })
+
+        for (const group of page.OtherGroups) {
+            put(group, "", -1)
+        }
+    }
+
+    // the large markers go first, so the small ones stay visible on top
+    return [...markers.values()].sort((a, b) => b.events.length - a.events.length)
+})
+
+// renderMap draws the basemap and the markers in an equirectangular projection fitted to them,
+// a marker's area grows with its events and clicking it shows them
+function renderMap() {
+    const markers = mapMarkers.val
+
+    const coordinates = [
+        ...basemap.lines.flat(),
+        ...basemap.points.map((point) => point.coordinates),
+        ...markers.map((marker) => [marker.point.Lon, marker.point.Lat]),
+    ]
+
+    if (!coordinates.length) {
+        return p({ style: `text-align: center; color: #666;` }, "Немає подій з координатами")
+    }
+
+    // a loop, since a detailed border has too many vertices to be spread into Math.min
+    let [minLon, maxLon, minLat, maxLat] = [Infinity, -Infinity, Infinity, -Infinity]
+    for (const [lon, lat] of coordinates) {
+        minLon = Math.min(minLon, lon)
+        maxLon = Math.max(maxLon, lon)
+        minLat = Math.min(minLat, lat)
+        maxLat = Math.max(maxLat, lat)
+    }
+
+    // a degree of longitude shrinks away from the equator
+    const k = Math.cos((minLat + maxLat) / 2 * Math.PI / 180)
+    const spanX = Math.max((maxLon - minLon) * k, 0.01)
+    const spanY = Math.max(maxLat - minLat, 0.01)
+
+    const width = 720
+    const padding = 24
+    const scale = Math.min((width - 2 * padding) / spanX, (540 - 2 * padding) / spanY)
+    const height = spanY * scale + 2 * padding
+    const left = (width - spanX * scale) / 2
+
+    const project = ([lon, lat]) => [
+        left + (lon - minLon) * k * scale,
+        height - padding - (lat - minLat) * scale,
+    ]
+
+    const path = (line) => line.map((c, i) => `${i ? "L" : "M"}${project(c).map((v) => v.toFixed(1)).join(",")}`).join("")
+
+    const out = svg.svg({ viewBox: `0 0 ${width} ${height.toFixed(0)}`, width: "100%", class: `map` },
+        basemap.lines.map((line) => svg.path({ d: path(line), class: `border` })),
+        basemap.points.map((point) => {
+            const [x, y] = project(point.coordinates)
+
+            return svg.g({ class: `post` },
+                svg.circle({ cx: x.toFixed(1), cy: y.toFixed(1), r: 2 }),
+                point.name && svg.text({ x: (x + 4).toFixed(1), y: (y - 4).toFixed(1) }, point.name),
+            )
+        }),
+        markers.map((marker) => {
+            const [x, y] = project([marker.point.Lon, marker.point.Lat])
+            const title = [...marker.titles].join(", ")
+
+            return svg.circle({
+                cx: x.toFixed(1),
+                cy: y.toFixed(1),
+                r: (3 + 3 * Math.sqrt(marker.events.length)).toFixed(1),
+                fill: mapColor(marker.index),
+                class: `marker clickable`,
+                onclick: () => selection.val = { title, events: marker.events },
+            }, svg.title(`${title}: ${marker.events.length}`))
+        }),
+    )
+
+    // the colors follow the dictionary index of the supergroups like the markers, empty supergroups keep theirs
+    const supergroups = []
+    for (const page of data.Pages) {
+        page.SelectedSupergroups.forEach((groups, index) => {
+            if (groups.length) {
+                supergroups[index] = groups[0].Name
+            }
+        })
+    }
+
+    const legend = div({ class: `options`, style: `flex-wrap: wrap; gap: 4px 8px;` },
+        supergroups.map((name, index) => span(span({ class: `swatch`, style: `background: ${mapColor(index)};` }), name)),
+        span(span({ class: `swatch`, style: `background: ${mapColor(-1)};` }), "Інші"),
+    )
+
+    return div(out, legend)
+}
+
+function afterCutoff(cutoff) {
+    return (e) => {
+        return !cutoff || e.End >= cutoff
+    }
+}
+
+document.addEventListener(
+    "DOMContentLoaded",
+    function () {
+        const container = document.getElementById('container')
+
+        renderData(container, data)
+    }
+)
+
+// the layout is shared with report.WriteChart, so the charts on the page look like the exported ones
+const chartLayout = {
+    width: 720,
+    timelineHeight: 200,
+    timelineLeft: 32,
+    timelineRight: 8,
+    timelineTop: 12,
+    timelineBelow: 24,
+    heatmapLeft: 160,
+    heatmapRight: 8,
+    heatmapTop: 20,
+    heatmapRow: 18,
+    heatmapName: 24,
+}
+
+// timeline counts the events by the hour they ended like processor.NewTimeline does;
+// posts are { name, events }, the other ones are sorted by name and left out without events
+function timeline(known, other) {
+    const hours = Array(24).fill(0)
+
+    const count = ({ name, events }) => {
+        const post = { name, hours: Array(24).fill(0) }
+
+        for (const event of events) {
+            post.hours[event.End % 24]++
+            hours[event.End % 24]++
+        }
+
+        return post
+    }
+
+    const posts = known.map(count)
+    const rest = other.filter((post) => post.events.length).map(count)
+    rest.sort((a, b) => a.name < b.name ? -1 : a.name > b.name ? 1 : 0)
+
+    return { hours, posts: [...posts, ...rest] }
+}
+
+function hour(value) {
+    return String(value).padStart(2, "0")
+}
+
+function chartRoot(height, ...children) {
+    return svg.svg({
+        viewBox: `0 0 ${chartLayout.width} ${height.toFixed(1)}`,
+        width: chartLayout.width,
+        height: height.toFixed(1),
+        "font-family": "sans-serif",
+        "font-size": 10,
+        class: `chart`,
+    }, children)
+}
+
+// hourLabels marks every third hour above or below the columns
+function hourLabels(left, slot, y) {
+    const out = []
+
+    for (let h = 0; h < 24; h += 3) {
+        out.push(svg.text({ x: (left + (h + 0.5) * slot).toFixed(1), y: y.toFixed(1), "text-anchor": "middle", fill: "#666" }, hour(h)))
+    }
+
+    return out
+}
+
+// renderChart draws the events of every hour as bars
+function renderChart(t) {
+    const { width, timelineHeight, timelineLeft, timelineRight, timelineTop, timelineBelow } = chartLayout
+
+    const bottom = timelineHeight - timelineBelow
+    const slot = (width - timelineLeft - timelineRight) / 24
+    const most = Math.max(1, ...t.hours)
+
+    return chartRoot(timelineHeight,
+        svg.line({ x1: timelineLeft, y1: bottom.toFixed(1), x2: width - timelineRight, y2: bottom.toFixed(1), stroke: "#888" }),
+        svg.text({ x: timelineLeft - 4, y: (timelineTop + 4).toFixed(1), "text-anchor": "end", fill: "#666" }, most),
+        svg.text({ x: timelineLeft - 4, y: bottom.toFixed(1), "text-anchor": "end", fill: "#666" }, "0"),
+        t.hours.map((count, h) => {
+            const height = count / most * (bottom - timelineTop)
+
+            return svg.rect({
+                x: (timelineLeft + h * slot + 1).toFixed(1),
+                y: (bottom - height).toFixed(1),
+                width: (slot - 2).toFixed(1),
+                height: height.toFixed(1),
+                fill: "#2a7ab0",
+            }, svg.title(`${hour(h)}:00 — ${count}`))
+        }),
+        hourLabels(timelineLeft, slot, bottom + 16),
+    )
+}
+
+// renderHeatmap draws a row of hours for every post, the more events the darker the cell
+function renderHeatmap(t) {
+    const { width, heatmapLeft, heatmapRight, heatmapTop, heatmapRow, heatmapName } = chartLayout
+
+    const cell = (width - heatmapLeft - heatmapRight) / 24
+    const most = Math.max(1, ...t.posts.flatMap((post) => post.hours))
+
+    return chartRoot(heatmapTop + t.posts.length * heatmapRow + 4,
+        hourLabels(heatmapLeft, cell, heatmapTop - 6),
+        t.posts.map((post, i) => {
+            const y = heatmapTop + i * heatmapRow
+
+            let name = [...post.name]
+            if (name.length > heatmapName) {
+                name = [...name.slice(0, heatmapName - 1), "…"]
+            }
+
+            return [
+                svg.text({ x: heatmapLeft - 4, y: (y + 13).toFixed(1), "text-anchor": "end" }, name.join(""), svg.title(post.name)),
+                post.hours.map((count, h) => svg.rect({
+                    x: (heatmapLeft + h * cell).toFixed(1),
+                    y: y.toFixed(1),
+                    width: (cell - 1).toFixed(1),
+                    height: heatmapRow - 1,
+                    fill: count ? "#b02a4a" : "#f6f6f6",
+                    "fill-opacity": count ? (0.15 + 0.85 * count / most).toFixed(2) : undefined,
+                }, svg.title(`${post.name}, ${hour(h)}:00 — ${count}`))),
+            ]
+        }),
+    )
+}
+
+// saveSVG downloads a chart as it is drawn
+function saveSVG(node, filename) {
+    const url = URL.createObjectURL(new Blob([new XMLSerializer().serializeToString(node)], { type: "image/svg+xml" }))
+
+    const link = document.createElement("a")
+    link.href = url
+    link.download = filename
+    link.click()
+
+    setTimeout(() => URL.revokeObjectURL(url))
+}
+
+// renderCharts draws the events by hour and the posts by hour, each of them can be saved
+function renderCharts(name, known, other) {
+    const t = timeline(known, other)
+
+    const bars = renderChart(t)
+    const heatmap = renderHeatmap(t)
+
+    return div({ class: `charts` },
+        bars,
+        heatmap,
+        div({ class: `options` },
+            button({ onclick: () => saveSVG(bars, `${name}-timeline.svg`) }, "Зберегти графік"),
+            button({ onclick: () => saveSVG(heatmap, `${name}-heatmap.svg`) }, "Зберегти теплову карту"),
+        ),
+    )
+}
+
+function groupTitle(group) {
+    return [group.Type, group.Name, group.Hint].filter(Boolean).join(" ")
+}
+
+// renderPageCharts draws the charts of the events left after the page cutoff
+function renderPageCharts(page) {
+    return renderCharts(
+        page.Filename,
+        page.SelectedSupergroups.filter((groups) => groups.length).map((groups) => ({ name: groups[0].Name, events: groups.rows() })),
+        page.OtherGroups.map((group) => ({ name: groupTitle(group), events: group.rows() })),
+    )
+}
+
+function calculateSpans(items) {
+    var last = -1
+    var span = 0
+
+    for (var i = 0; i < items.length + 1; i++) {
+        if (items[last] && items[last].value) {
+            items[last].span += span
+            span = 0
+        }
+
+        if (items[i] && items[i].value) {
+            last = i
+            continue
+        }
+
+        // only count spans if there is an empty cell
+        span++
+    }
+}
+
+function renderGroups(groups, divider, sum, draggable) {
+    if (!groups) {
+        console.log(groups, divider)
+        return
+    }
+
+    var out = div({ class: `table` })
+
+    divider && add(out, div({ class: 'divider' }, divider))
+
+    var total = 0
+
+    for (group of groups) {
+        const components = [
+            { value: group.Type, span: 1 },
+            { value: group.Name, span: 1 },
+            { value: group.Hint, span: 1 },
+        ]
+
+        calculateSpans(components)
+
+
+        // TODO: make sure the original events are transferred, so they can be filtered
+        // make sure there is a back button
+        // allow the entries to be selected
+        for (component of components) {
+            component.value && add(out, div({ style: `grid-column: span ${component.span};`, draggable: draggable, ondragstart: (e)=>e.dataTransfer.setData("text/plain", "hello"), ondragover: (e)=>e.preventDefault(), ondragenter:(e)=>e.target.style.background="#eee", ondragleave:(e)=>e.target.style.background="none", ondrop: (e)=>{e.preventDefault();e.target.style.background="none";console.log("drop", e.dataTransfer);alert("drop")} }, component.value))
+        }
+
+        const title = [group.Type, group.Name, group.Hint].filter(Boolean).join(" ")
+        const rows = group.rows
+
+        add(out, div({ class: `number clickable`, onclick: () => rows && (selection.val = { title: title, events: rows() }) }, group.filtered))
+    }
+
+    sum && add(out, div({ class: `number clickable`, style: `grid-column: span 4; font-weight:bold`, onclick: () => groups.rows && (selection.val = { title: divider, events: groups.rows() }) }, groups.total))
+
+    return out
+}
+
+function renderSupergroups(supergroups) {
+    var out = []
+
+    for (groups of supergroups.filter((groups) => groups.length)) {
+        out.push(
+            add(renderGroups(groups, groups[0].Name, true))
+        )
+    }
+
+    return out
+}
+
+// renderRecords shows every column of the events read from a page in table order
+function renderRecords(page) {
+    let events = []
+
+    for (const groups of page.SelectedSupergroups) {
+        for (const group of groups) {
+            events.push(...group.Events)
+        }
+    }
+
+    for (const group of page.OtherGroups) {
+        events.push(...group.Events)
+    }
+
+    events = events.filter(afterCutoff(page.cutoff.val))
+    events.sort((a, b) => a.End - b.End)
+
+    const columns = page.Columns || []
+
+    var out = div({ class: `records`, style: `grid-template-columns: repeat(${columns.length}, auto);` })
+
+    for (const column of columns) {
+        add(out, div({ class: `column` }, column))
+    }
+
+    for (const event of events) {
+        for (const column of columns) {
+            add(out, div((event.Attributes || {})[column] || ""))
+        }
+    }
+
+    return out
+}
+
+// selection holds the rows behind the count that was clicked last
+let selection = van.state(null)
+
+// renderSelection shows the contributing rows as they were read from the documents
+function renderSelection() {
+    if (!selection.val) {
+        return div()
+    }
+
+    const { title, events } = selection.val
+
+    var out = div({ class: `selection` },
+        div({ class: `options` },
+            p({ style: `font-weight: bold` }, `${title}: ${events.length}`),
+            button({ onclick: () => selection.val = null }, "Закрити"),
+        ),
+    )
+
+    for (const event of events) {
+        const source = event.Provenance || {}
+        const cells = source.Cells || []
+
+        add(out,
+            div({ class: `source` }, `${source.File || ""}, рядок ${source.Row || "?"}`),
+            div({ class: `records`, style: `grid-template-columns: repeat(${cells.length}, auto);` },
+                cells.map((cell) => div(cell)),
+            ),
+        )
+    }
+
+    return out
+}
+
+function renderPages(pages) {
+    var out = []
+    var folder = ""
+    for (let page of pages) {
+        // units send one folder each, so pages are introduced by their folder
+        if (page.Folder && page.Folder !== folder) {
+            out.push(div({ class: "divider" }, page.Folder))
+        }
+        folder = page.Folder
+
+        out.push(
+            div({ class: "header" }, page.Filename.slice(page.Folder ? page.Folder.length + 1 : 0)),
+            div({ class: "options", style: `gap: 4px` },
+                input({ type: "checkbox", onchange: (e) => page.cutoff.val = e.target.checked ? nightStart : 0 }),
+                p(`${String(nightStart).padStart(2, "0")}:00-00:00`),
+                input({ type: "checkbox", onchange: (e) => page.columns.val = e.target.checked }),
+                p("Усі колонки"),
+                input({ type: "checkbox", onchange: (e) => page.chart.val = e.target.checked }),
+                p("Погодинно"),
+            ),
+            () => page.columns.val ? renderRecords(page) : div(),
+            () => page.chart.val ? renderPageCharts(page) : div(),
+            div({ class: "matrix" },
+                renderSupergroups(page.SelectedSupergroups), // "Відомі"
+            ),
+            renderGroups(page.OtherGroups, "Інші"),
+        )
+    }
+
+    return out
+}
+
+function renderData(container, data) {
+    add(document.body, () => renderSelection())
+
+    add(container,
+        renderPages(data.Pages),
+        div({ class: "header" }, "Підсумок"),
+        () => renderGroups(aggregateOther.val, "Невідомі за всі документи", 0, true),
+        () => renderGroups(aggregateSelected.val, "Сума за всі документи", 0),
+        div({ class: `header` }, "Мапа"),
+        () => renderMap(),
+        div({ class: `header` }, "Погодинно"),
+        () => renderCharts(
+            "all",
+            aggregateSelected.val.map((group) => ({ name: group.Name, events: group.rows() })),
+            aggregateOther.val.map((group) => ({ name: groupTitle(group), events: group.rows() })),
+        ),
+        div({ class: `header` }, "Примітки"),
+        () => { return renderedComments.val },
+        () => renderDropped(),
+        div({ class: `header` }, "Зведення"),
+        div({ class: `options`, style: `flex-wrap: wrap; gap: 4px 8px;` },
+            pivotFields.map((field) => [
+                input({
+                    type: "checkbox",
+                    onchange: (e) => pivotBy.val = e.target.checked
+                        ? [...pivotBy.val, field.key]
+                        : pivotBy.val.filter((key) => key !== field.key),
+                }),
+                p(field.label),
+            ]),
+        ),
+        () => renderPivot(),
+        div({ class: `header` }, "Пояснення"),
+        div({ class: `options`, style: `gap: 4px` },
+            input({ type: "checkbox", onchange: (e) => explain.val = e.target.checked }),
+            p("Показати"),
+            input({ type: "checkbox", onchange: (e) => explainOther.val = e.target.checked }),
+            p("Лише інші"),
+        ),
+        () => renderExplanations(),
+    )
+
+
+    add(container,
+        textarea(
+            {
+                id: `summary`,
+                rows: 12,
+                value: () => summary.val,
+                style: `border-radius: 12px; border: 1px solid #eee; padding: 6px;`,
+            },
+        ),
+        div({ class: `options` },
+            button({ onclick: () => { navigator.clipboard.writeText(document.getElementById('summary').value); alert('Скопійовано!') } }, "Скопіювати"),
+            button({ onclick: () => { navigator.share({ text: document.getElementById('summary').value }); } }, "Поділитися"),
+        ),
+    )
+
+}
@@ -0,0 +1,181 @@
+// Local build of the VanJS API surface used by the report page
+// (van.tags, van.add, van.state, van.derive). It is served from /static so
+// the page works without any external network access.
+(() => {
+    let curDeps
+    let changed = new Set()
+    let timer
+
+    const protoOf = Object.getPrototypeOf
+    const stateProto = {}
+
+    const isState = (v) => v !== null && typeof v === "object" && protoOf(v) === stateProto
+
+    const setterFor = (dom, k) => {
+        for (let proto = protoOf(dom); proto; proto = protoOf(proto)) {
+            const desc = Object.getOwnPropertyDescriptor(proto, k)
+            if (desc) {
+                return desc.set
+            }
+        }
+    }
+
+    // every derived state keeps a single listener, so dependencies that did not change
+    // do not collect another one each time it is recomputed
+    const runDerive = (f, s, listener) => {
+        const prev = curDeps
+        curDeps = new Set()
+        let value
+        try {
+            value = f()
+        } finally {
+            const deps = curDeps
+            curDeps = prev
+            for (const d of deps) {
+                d._listeners.add(listener)
+            }
+        }
+        s.val = value
+        return s
+    }
+
+    // a state drops the bindings whose nodes left the page a while after it gained one,
+    // so sections that were rendered again do not keep the old bindings alive
+    let collected = new Set()
+    let gcTimer
+
+    const gc = () => {
+        gcTimer = undefined
+        for (const s of collected) {
+            s._bindings = new Set([...s._bindings].filter((b) => b.dom.isConnected))
+        }
+        collected = new Set()
+    }
+
+    const flush = () => {
+        timer = undefined
+        const bindings = new Set()
+        for (const s of changed) {
+            for (const b of s._bindings) {
+                b.dom.isConnected ? bindings.add(b) : s._bindings.delete(b)
+            }
+        }
+        changed = new Set()
+        for (const b of bindings) {
+            b.update()
+        }
+    }
+
+    Object.defineProperty(stateProto, "val", {
+        get() {
+            curDeps?.add(this)
+            return this._val
+        },
+        set(v) {
+            if (v === this._val) {
+                return
+            }
+            this._val = v
+            const listeners = this._listeners
+            this._listeners = new Set()
+            for (const l of listeners) {
+                l()
+            }
+            changed.add(this)
+            timer ??= setTimeout(flush)
+        },
+    })
+
+    const state = (initVal) => {
+        const s = Object.create(stateProto)
+        s._val = initVal
+        s._listeners = new Set()
+        s._bindings = new Set()
+        return s
+    }
+
+    const derive = (f) => {
+        const s = state()
+        const listener = () => runDerive(f, s, listener)
+        return runDerive(f, s, listener)
+    }
+
+    const toNode = (v) => v instanceof Node ? v : document.createTextNode(String(v))
+
+    const bind = (f, dom) => {
+        const binding = { dom }
+        binding.update = () => {
+            const prev = curDeps
+            curDeps = new Set()
+            let value
+            try {
+                value = f(binding.dom)
+            } finally {
+                const deps = curDeps
+                curDeps = prev
+                for (const d of deps) {
+                    d._bindings.add(binding)
+                    collected.add(d)
+                }
+                gcTimer ??= setTimeout(gc, 1000)
+            }
+            const node = value === null || value === undefined ? document.createTextNode("") : toNode(value)
+            if (binding.dom && binding.dom !== node) {
+                binding.dom.replaceWith(node)
+            }
+            binding.dom = node
+        }
+        binding.update()
+        return binding.dom
+    }
+
+    const add = (dom, ...children) => {
+        for (const c of children.flat(Infinity)) {
+            if (c === null || c === undefined || c === false) {
+                continue
+            }
+            if (isState(c)) {
+                dom.append(bind(() => c.val))
+            } else if (typeof c === "function") {
+                dom.append(bind(c))
+            } else {
+                dom.append(toNode(c))
+            }
+        }
+        return dom
+    }
+
+    const setProp = (dom, k, v) => {
+        if (k.startsWith("on")) {
+            dom[k] = v
+            return
+        }
+        const setter = setterFor(dom, k)
+        const assign = setter ? (v) => setter.call(dom, v) : (v) => dom.setAttribute(k, v)
+        if (isState(v)) {
+            bind(() => (assign(v.val), dom))
+        } else if (typeof v === "function") {
+            bind(() => (assign(v()), dom))
+        } else if (v !== undefined && v !== null) {
+            assign(v)
+        }
+    }
+
+    const tag = (ns, name, ...args) => {
+        const [props, ...children] = protoOf(args[0] ?? 0) === Object.prototype ? args : [{}, ...args]
+        const dom = ns ? document.createElementNS(ns, name) : document.createElement(name)
+        for (const [k, v] of Object.entries(props)) {
+            setProp(dom, k, v)
+        }
+        return add(dom, children)
+    }
+
+    const tags = (ns) => new Proxy({}, { get: (_, name) => tag.bind(undefined, ns, name) })
+
+    window.van = {
+        add,
+        state,
+        derive,
+        tags: new Proxy((ns) => tags(ns), { get: (_, name) => tag.bind(undefined, undefined, name) }),
+    }
+})()
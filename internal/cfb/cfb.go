@@ -0,0 +1,292 @@
+// Package cfb reads streams from Compound File Binary files (MS-CFB),
+// the container of legacy Word documents and of encrypted Office documents
+package cfb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Signature starts every compound file
+var Signature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+var ErrNotCompound = errors.New("not a compound file")
+
+const (
+	// sector numbers from fatSector up mark the end of a chain or special sectors
+	fatSector   = 0xFFFFFFFD
+	noStream    = 0xFFFFFFFF
+	headerSize  = 512
+	entrySize   = 128
+	streamEntry = 2
+	rootEntry   = 5
+)
+
+// File is an opened compound file
+type File struct {
+	r          io.ReaderAt
+	size       int64
+	sectorSize int64
+	miniSize   int64
+	miniCutoff uint64
+	fat        []uint32
+	miniFAT    []uint32
+	entries    []entry
+	miniStream []byte
+}
+
+type entry struct {
+	name  string
+	kind  byte
+	left  uint32
+	right uint32
+	child uint32
+	start uint32
+	size  uint64
+}
+
+// Open reads the header, the allocation tables and the directory
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	header := make([]byte, headerSize)
+
+	_, err := r.ReadAt(header, 0)
+	if err != nil || !bytes.Equal(header[:8], Signature) {
+		return nil, ErrNotCompound
+	}
+
+	sectorShift := binary.LittleEndian.Uint16(header[0x1E:])
+	miniShift := binary.LittleEndian.Uint16(header[0x20:])
+	if (sectorShift != 9 && sectorShift != 12) || miniShift != 6 {
+		return nil, fmt.Errorf("unsupported sector sizes %d/%d", sectorShift, miniShift)
+	}
+
+	f := &File{
+		r:          r,
+		size:       size,
+		sectorSize: 1 << sectorShift,
+		miniSize:   1 << miniShift,
+		miniCutoff: uint64(binary.LittleEndian.Uint32(header[0x38:])),
+	}
+
+	fatSectors := binary.LittleEndian.Uint32(header[0x2C:])
+	firstDirectory := binary.LittleEndian.Uint32(header[0x30:])
+	firstMiniFAT := binary.LittleEndian.Uint32(header[0x3C:])
+	firstDIFAT := binary.LittleEndian.Uint32(header[0x44:])
+	difatSectors := binary.LittleEndian.Uint32(header[0x48:])
+
+	if int64(fatSectors)*f.sectorSize > size {
+		return nil, errors.New("corrupt compound file header")
+	}
+
+	// the header holds the first 109 FAT sector locations, the DIFAT chain the rest
+	locations := []uint32{}
+	for i := range 109 {
+		locations = append(locations, binary.LittleEndian.Uint32(header[0x4C+i*4:]))
+	}
+
+	next := firstDIFAT
+	for i := uint32(0); i < difatSectors && next < fatSector; i++ {
+		sector, err := f.sector(next)
+		if err != nil {
+			return nil, err
+		}
+
+		perSector := len(sector)/4 - 1
+		for j := range perSector {
+			locations = append(locations, binary.LittleEndian.Uint32(sector[j*4:]))
+		}
+
+		next = binary.LittleEndian.Uint32(sector[perSector*4:])
+	}
+
+	for _, location := range locations[:min(int(fatSectors), len(locations))] {
+		sector, err := f.sector(location)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := 0; j < len(sector); j += 4 {
+			f.fat = append(f.fat, binary.LittleEndian.Uint32(sector[j:]))
+		}
+	}
+
+	directory, err := f.chain(firstDirectory, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+entrySize <= len(directory); i += entrySize {
+		e := parseEntry(directory[i : i+entrySize])
+
+		// version 3 files may leave garbage in the high half of the size
+		if f.sectorSize == 512 {
+			e.size &= 0xFFFFFFFF
+		}
+
+		f.entries = append(f.entries, e)
+	}
+
+	if len(f.entries) == 0 || f.entries[0].kind != rootEntry {
+		return nil, errors.New("compound file without a root entry")
+	}
+
+	miniFAT, err := f.chain(firstMiniFAT, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	for j := 0; j+4 <= len(miniFAT); j += 4 {
+		f.miniFAT = append(f.miniFAT, binary.LittleEndian.Uint32(miniFAT[j:]))
+	}
+
+	root := f.entries[0]
+	if root.start < fatSector {
+		f.miniStream, err = f.chain(root.start, int64(root.size))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func parseEntry(data []byte) entry {
+	nameLength := int(binary.LittleEndian.Uint16(data[0x40:]))
+	nameLength = min(max(nameLength-2, 0), 62) / 2
+
+	name := make([]uint16, nameLength)
+	for i := range name {
+		name[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	return entry{
+		name:  string(utf16.Decode(name)),
+		kind:  data[0x42],
+		left:  binary.LittleEndian.Uint32(data[0x44:]),
+		right: binary.LittleEndian.Uint32(data[0x48:]),
+		child: binary.LittleEndian.Uint32(data[0x4C:]),
+		start: binary.LittleEndian.Uint32(data[0x74:]),
+		size:  binary.LittleEndian.Uint64(data[0x78:]),
+	}
+}
+
+func (f *File) sector(n uint32) ([]byte, error) {
+	offset := (int64(n) + 1) * f.sectorSize
+	if offset+f.sectorSize > f.size+f.sectorSize {
+		return nil, fmt.Errorf("sector %d is out of range", n)
+	}
+
+	out := make([]byte, f.sectorSize)
+
+	read, err := f.r.ReadAt(out, offset)
+	if err != nil && !(err == io.EOF && read > 0) {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// chain follows the FAT from the start sector, size below zero reads the whole chain
+func (f *File) chain(start uint32, size int64) ([]byte, error) {
+	out := []byte{}
+
+	for n, steps := start, 0; n < fatSector; n, steps = f.fat[n], steps+1 {
+		if int(n) >= len(f.fat) || steps > len(f.fat) {
+			return nil, errors.New("corrupt sector chain")
+		}
+
+		if size >= 0 && int64(len(out)) >= size {
+			break
+		}
+
+		sector, err := f.sector(n)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, sector...)
+	}
+
+	if size >= 0 {
+		if int64(len(out)) < size {
+			return nil, errors.New("stream is shorter than its size")
+		}
+
+		out = out[:size]
+	}
+
+	return out, nil
+}
+
+func (f *File) miniChain(start uint32, size int64) ([]byte, error) {
+	out := []byte{}
+
+	for n, steps := start, 0; n < fatSector && int64(len(out)) < size; n, steps = f.miniFAT[n], steps+1 {
+		if int(n) >= len(f.miniFAT) || steps > len(f.miniFAT) {
+			return nil, errors.New("corrupt mini sector chain")
+		}
+
+		offset := int64(n) * f.miniSize
+		if offset+f.miniSize > int64(len(f.miniStream)) {
+			return nil, errors.New("mini sector is out of range")
+		}
+
+		out = append(out, f.miniStream[offset:offset+f.miniSize]...)
+	}
+
+	if int64(len(out)) < size {
+		return nil, errors.New("stream is shorter than its size")
+	}
+
+	return out[:size], nil
+}
+
+// children walks the red-black tree of the storage's children
+func (f *File) children(storage int) (out []int) {
+	visited := map[uint32]bool{}
+
+	var walk func(n uint32)
+	walk = func(n uint32) {
+		if n == noStream || int(n) >= len(f.entries) || visited[n] {
+			return
+		}
+
+		visited[n] = true
+
+		walk(f.entries[n].left)
+		out = append(out, int(n))
+		walk(f.entries[n].right)
+	}
+
+	walk(f.entries[storage].child)
+
+	return
+}
+
+// Stream reads a stream under the root storage, names compare case-insensitively like in MS-CFB
+func (f *File) Stream(name string) ([]byte, error) {
+	for _, i := range f.children(0) {
+		e := f.entries[i]
+
+		if e.kind != streamEntry || !strings.EqualFold(e.name, name) {
+			continue
+		}
+
+		if e.size > uint64(f.size) {
+			return nil, fmt.Errorf("stream %s is larger than the file", name)
+		}
+
+		if e.size < f.miniCutoff {
+			return f.miniChain(e.start, int64(e.size))
+		}
+
+		return f.chain(e.start, int64(e.size))
+	}
+
+	return nil, fmt.Errorf("stream %s not found", name)
+}
@@ -0,0 +1,103 @@
+// Package cfbtest writes small compound files for tests
+package cfbtest
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// Build writes a version 3 compound file with the streams under the root storage,
+// streams are padded to the mini stream cutoff so they all live in regular sectors
+func Build(names []string, streams [][]byte) []byte {
+	const sector = 512
+
+	le := binary.LittleEndian
+
+	fat := []uint32{0xFFFFFFFD, 0xFFFFFFFE}
+	data := []byte{}
+	starts := []uint32{}
+
+	for i := range streams {
+		for len(streams[i]) < 4096 || len(streams[i])%sector != 0 {
+			streams[i] = append(streams[i], 0)
+		}
+
+		start := uint32(len(fat))
+		starts = append(starts, start)
+
+		count := uint32(len(streams[i]) / sector)
+		for k := range count {
+			if k == count-1 {
+				fat = append(fat, 0xFFFFFFFE)
+			} else {
+				fat = append(fat, start+k+1)
+			}
+		}
+
+		data = append(data, streams[i]...)
+	}
+
+	for len(fat) < sector/4 {
+		fat = append(fat, 0xFFFFFFFF)
+	}
+
+	header := make([]byte, sector)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	le.PutUint16(header[0x18:], 0x3E)
+	le.PutUint16(header[0x1A:], 3)
+	le.PutUint16(header[0x1C:], 0xFFFE)
+	le.PutUint16(header[0x1E:], 9)
+	le.PutUint16(header[0x20:], 6)
+	le.PutUint32(header[0x2C:], 1)
+	le.PutUint32(header[0x30:], 1)
+	le.PutUint32(header[0x38:], 4096)
+	le.PutUint32(header[0x3C:], 0xFFFFFFFE)
+	le.PutUint32(header[0x44:], 0xFFFFFFFE)
+	for i := range 109 {
+		le.PutUint32(header[0x4C+i*4:], 0xFFFFFFFF)
+	}
+	le.PutUint32(header[0x4C:], 0)
+
+	entry := func(name string, kind byte, right, child, start uint32, size int) []byte {
+		out := make([]byte, 128)
+
+		encoded := utf16.Encode([]rune(name))
+		for i, u := range encoded {
+			le.PutUint16(out[i*2:], u)
+		}
+
+		le.PutUint16(out[0x40:], uint16(len(encoded)*2+2))
+		out[0x42] = kind
+		le.PutUint32(out[0x44:], 0xFFFFFFFF)
+		le.PutUint32(out[0x48:], right)
+		le.PutUint32(out[0x4C:], child)
+		le.PutUint32(out[0x74:], start)
+		le.PutUint64(out[0x78:], uint64(size))
+
+		return out
+	}
+
+	// the root's children hang off each other as right siblings
+	directory := entry("Root Entry", 5, 0xFFFFFFFF, 1, 0xFFFFFFFE, 0)
+	for i, name := range names {
+		right := uint32(i + 2)
+		if i == len(names)-1 {
+			right = 0xFFFFFFFF
+		}
+
+		directory = append(directory, entry(name, 2, right, 0xFFFFFFFF, starts[i], len(streams[i]))...)
+	}
+
+	for len(directory) < sector {
+		directory = append(directory, entry("", 0, 0xFFFFFFFF, 0xFFFFFFFF, 0, 0)...)
+	}
+
+	out := append(header, make([]byte, sector)...)
+	for i, next := range fat {
+		le.PutUint32(out[sector+i*4:], next)
+	}
+
+	out = append(out, directory...)
+
+	return append(out, data...)
+}
@@ -0,0 +1,80 @@
+package document
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// ReadCSV reads a delimited text table, a zero comma is guessed from the first line;
+// line breaks within a quoted field separate its paragraphs like in Word tables
+func ReadCSV(r io.ReaderAt, size int64, comma rune) (Table, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return Table{}, err
+	}
+
+	text := decodeText(data)
+
+	if comma == 0 {
+		comma = guessComma(text)
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	out := Table{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+
+		out.Rows = append(out.Rows, Row{Cells: splitCells(record)})
+	}
+
+	if len(out.Rows) == 0 {
+		return out, ErrNoTable
+	}
+
+	return out, nil
+}
+
+// guessComma picks the most frequent separator of the first line,
+// Excel writes semicolons where the decimal separator is a comma
+func guessComma(text string) rune {
+	line, _, _ := strings.Cut(text, "\n")
+
+	comma, count := ',', strings.Count(line, ",")
+
+	for _, candidate := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(candidate)); n > count {
+			comma, count = candidate, n
+		}
+	}
+
+	return comma
+}
+
+// splitCells turns values into cells, line breaks separate paragraphs and empty values have none
+func splitCells(values []string) []Cell {
+	cells := make([]Cell, 0, len(values))
+
+	for _, value := range values {
+		cell := Cell{}
+
+		if value != "" {
+			cell.Paragraphs = strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+		}
+
+		cells = append(cells, cell)
+	}
+
+	return cells
+}
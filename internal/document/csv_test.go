@@ -0,0 +1,25 @@
+package document
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	// Windows-1251 with semicolons, as Excel saves it in Ukrainian
+	data := []byte("\xd7\xe0\xf1;\xcf\xb3\xe4\xf0\xee\xe7\xe4\xb3\xeb\n19:10;\"\xe2\xef\xf1 \xca\xee\xe4\xe8\xec\xe0\r\n\xf0\xe0\xe9\xee\xed\";\n")
+
+	table, err := ReadCSV(bytes.NewReader(data), int64(len(data)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 2 || table.Rows[0].Cells[1].Text() != "Підрозділ" {
+		t.Fatalf("unexpected table %v", table.Rows)
+	}
+
+	name := table.Rows[1].Cells[1]
+	if len(name.Paragraphs) != 2 || name.Paragraphs[0] != "впс Кодима" || name.Paragraphs[1] != "район" || len(table.Rows[1].Cells[2].Paragraphs) != 0 {
+		t.Errorf("unexpected row %q", table.Rows[1].Cells)
+	}
+}
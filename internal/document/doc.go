@@ -0,0 +1,490 @@
+package document
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf16"
+
+	"go-doc-parser/internal/cfb"
+)
+
+var ErrEncrypted = errors.New("the document is encrypted")
+
+const (
+	docIdent   = 0xA5EC
+	docFkpSize = 512
+
+	// paragraph properties marking table paragraphs
+	sprmPFInTable        = 0x2416
+	sprmPFTtp            = 0x2417
+	sprmPFInnerTableCell = 0x244B
+	sprmPFInnerTtp       = 0x244C
+	sprmPItap            = 0x6649
+	sprmPChgTabs         = 0xC615
+	sprmTDefTable        = 0xD608
+)
+
+// ReadDoc reads the first table of a Word 97-2003 binary document:
+// the text comes from the piece table, the table structure from the paragraph properties,
+// cells end with 0x07 and the row end mark is a 0x07 paragraph flagged as the table terminating paragraph
+func ReadDoc(r io.ReaderAt, size int64) (Table, error) {
+	file, err := cfb.Open(r, size)
+	if err != nil {
+		return Table{}, err
+	}
+
+	word, err := file.Stream("WordDocument")
+	if err != nil {
+		return Table{}, err
+	}
+
+	fib, err := readFIB(word)
+	if err != nil {
+		return Table{}, err
+	}
+
+	name := "0Table"
+	if fib.whichTable {
+		name = "1Table"
+	}
+
+	table, err := file.Stream(name)
+	if err != nil {
+		return Table{}, err
+	}
+
+	pieces, err := readPieces(slice(table, fib.fcClx, fib.lcbClx))
+	if err != nil {
+		return Table{}, err
+	}
+
+	paragraphs, err := readParagraphs(word, slice(table, fib.fcPlcfBtePapx, fib.lcbPlcfBtePapx))
+	if err != nil {
+		return Table{}, err
+	}
+
+	return readDocTable(docText(word, pieces, fib.ccpText), paragraphs)
+}
+
+// docFIB is the part of the file information block the reader needs
+type docFIB struct {
+	whichTable     bool
+	ccpText        uint32
+	fcClx          uint32
+	lcbClx         uint32
+	fcPlcfBtePapx  uint32
+	lcbPlcfBtePapx uint32
+}
+
+func readFIB(word []byte) (docFIB, error) {
+	out := docFIB{}
+
+	if len(word) < 34 || binary.LittleEndian.Uint16(word) != docIdent {
+		return out, errors.New("not a Word document")
+	}
+
+	if nFib := binary.LittleEndian.Uint16(word[2:]); nFib < 0xC0 {
+		return out, fmt.Errorf("Word 6/95 documents are not supported (nFib %#x)", nFib)
+	}
+
+	flags := binary.LittleEndian.Uint16(word[0x0A:])
+	if flags&0x0100 != 0 || flags&0x8000 != 0 {
+		return out, ErrEncrypted
+	}
+
+	out.whichTable = flags&0x0200 != 0
+
+	// FibBase, then the counted arrays fibRgW, fibRgLw and fibRgFcLcbBlob
+	offset := 32
+	csw := int(binary.LittleEndian.Uint16(word[offset:]))
+	offset += 2 + csw*2
+
+	if offset+2 > len(word) {
+		return out, errors.New("truncated file information block")
+	}
+
+	cslw := int(binary.LittleEndian.Uint16(word[offset:]))
+	rgLw := offset + 2
+	offset = rgLw + cslw*4
+
+	if cslw < 4 || offset+2 > len(word) {
+		return out, errors.New("truncated file information block")
+	}
+
+	out.ccpText = binary.LittleEndian.Uint32(word[rgLw+3*4:])
+
+	pairs := int(binary.LittleEndian.Uint16(word[offset:]))
+	blob := offset + 2
+
+	if pairs < 34 || blob+pairs*8 > len(word) {
+		return out, errors.New("truncated file information block")
+	}
+
+	pair := func(i int) (uint32, uint32) {
+		return binary.LittleEndian.Uint32(word[blob+i*8:]), binary.LittleEndian.Uint32(word[blob+i*8+4:])
+	}
+
+	out.fcPlcfBtePapx, out.lcbPlcfBtePapx = pair(13)
+	out.fcClx, out.lcbClx = pair(33)
+
+	return out, nil
+}
+
+// slice returns nil when the stream is shorter than the structure claims
+func slice(data []byte, fc, lcb uint32) []byte {
+	if uint64(fc)+uint64(lcb) > uint64(len(data)) {
+		return nil
+	}
+
+	return data[fc : fc+lcb]
+}
+
+// docPiece maps a range of character positions to the place in the WordDocument stream holding them
+type docPiece struct {
+	start, end uint32 // character positions
+	offset     uint32 // bytes
+	compressed bool   // one cp1252 byte per character instead of UTF-16
+}
+
+func readPieces(clx []byte) ([]docPiece, error) {
+	// skip the Prc property records until the Pcdt piece table
+	i := 0
+	for i < len(clx) && clx[i] == 0x01 {
+		if i+3 > len(clx) {
+			break
+		}
+
+		// a negative size would move back through the records and never reach the piece table
+		cbGrpprl := int(int16(binary.LittleEndian.Uint16(clx[i+1:])))
+		if cbGrpprl < 0 {
+			return nil, errors.New("malformed Clx")
+		}
+
+		i += 3 + cbGrpprl
+	}
+
+	if i+5 > len(clx) || clx[i] != 0x02 {
+		return nil, errors.New("no piece table")
+	}
+
+	plc := clx[i+5:]
+	if size := int(binary.LittleEndian.Uint32(clx[i+1:])); size <= len(plc) {
+		plc = plc[:size]
+	}
+
+	n := (len(plc) - 4) / 12
+	if n < 1 {
+		return nil, errors.New("empty piece table")
+	}
+
+	out := []docPiece{}
+
+	for k := range n {
+		fc := binary.LittleEndian.Uint32(plc[(n+1)*4+k*8+2:])
+
+		piece := docPiece{
+			start:      binary.LittleEndian.Uint32(plc[k*4:]),
+			end:        binary.LittleEndian.Uint32(plc[(k+1)*4:]),
+			offset:     fc & 0x3FFFFFFF,
+			compressed: fc&0x40000000 != 0,
+		}
+
+		if piece.compressed {
+			piece.offset /= 2
+		}
+
+		out = append(out, piece)
+	}
+
+	return out, nil
+}
+
+// docChar is a character of the main text with its offset in the WordDocument stream
+type docChar struct {
+	value  uint16
+	offset uint32
+}
+
+// docText reads the main document text, the first ccpText character positions
+func docText(word []byte, pieces []docPiece, ccpText uint32) []docChar {
+	out := []docChar{}
+
+	for _, piece := range pieces {
+		for cp := piece.start; cp < piece.end && cp < ccpText; cp++ {
+			if piece.compressed {
+				offset := piece.offset + cp - piece.start
+				if int(offset) >= len(word) {
+					break
+				}
+
+				out = append(out, docChar{value: cp1252(word[offset]), offset: offset})
+				continue
+			}
+
+			offset := piece.offset + (cp-piece.start)*2
+			if int(offset)+2 > len(word) {
+				break
+			}
+
+			out = append(out, docChar{value: binary.LittleEndian.Uint16(word[offset:]), offset: offset})
+		}
+	}
+
+	return out
+}
+
+// docParagraph holds the table flags of the paragraphs ending in a range of the WordDocument stream
+type docParagraph struct {
+	start, end uint32
+	inTable    bool
+	ttp        bool // the mark ends a table row
+	innerTtp   bool // the mark ends a row of a nested table
+	innerCell  bool // the mark ends a cell of a nested table
+	depth      int  // itap, how deep the table of the paragraph is nested, 1 for the outer table
+}
+
+// nested tells whether the paragraph belongs to a table within a cell, its text stays in that cell
+func (p docParagraph) nested() bool {
+	return p.depth > 1 || p.innerCell || p.innerTtp
+}
+
+// readParagraphs reads the paragraph properties from the PAPX FKP pages listed in PlcBtePapx
+func readParagraphs(word, plcBtePapx []byte) ([]docParagraph, error) {
+	n := (len(plcBtePapx) - 4) / 8
+	if n < 1 {
+		return nil, errors.New("no paragraph properties")
+	}
+
+	out := []docParagraph{}
+
+	for k := range n {
+		pn := binary.LittleEndian.Uint32(plcBtePapx[(n+1)*4+k*4:]) & 0x3FFFFF
+
+		page := slice(word, pn*docFkpSize, docFkpSize)
+		if page == nil {
+			return nil, fmt.Errorf("paragraph properties page %d is out of range", pn)
+		}
+
+		runs := int(page[docFkpSize-1])
+		if 4*(runs+1)+13*runs > docFkpSize-1 {
+			return nil, fmt.Errorf("corrupt paragraph properties page %d", pn)
+		}
+
+		for i := range runs {
+			paragraph := docParagraph{
+				start: binary.LittleEndian.Uint32(page[i*4:]),
+				end:   binary.LittleEndian.Uint32(page[(i+1)*4:]),
+			}
+
+			// BxPap points to the PapxInFkp in words, zero means default properties
+			if bx := int(page[4*(runs+1)+13*i]) * 2; bx != 0 {
+				paragraph.apply(papx(page, bx))
+			}
+
+			out = append(out, paragraph)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].start < out[j].start
+	})
+
+	return out, nil
+}
+
+// papx returns the grpprl of the PapxInFkp at the offset without the leading style index
+func papx(page []byte, offset int) []byte {
+	if offset >= len(page) {
+		return nil
+	}
+
+	cb := int(page[offset])
+	offset++
+
+	size := 2*cb - 1
+	if cb == 0 {
+		if offset >= len(page) {
+			return nil
+		}
+
+		size = 2 * int(page[offset])
+		offset++
+	}
+
+	if size < 2 || offset+size > len(page) {
+		return nil
+	}
+
+	return page[offset+2 : offset+size]
+}
+
+func (p *docParagraph) apply(grpprl []byte) {
+	for i := 0; i+2 <= len(grpprl); {
+		sprm := binary.LittleEndian.Uint16(grpprl[i:])
+		i += 2
+
+		size := operandSize(sprm, grpprl[i:])
+		if size < 0 || i+size > len(grpprl) {
+			return
+		}
+
+		operand := grpprl[i : i+size]
+		i += size
+
+		switch sprm {
+		case sprmPFInTable:
+			p.inTable = p.inTable || operand[0] != 0
+		case sprmPFInnerTableCell:
+			p.innerCell = operand[0] != 0
+			p.inTable = p.inTable || p.innerCell
+		case sprmPFTtp:
+			p.ttp = operand[0] != 0
+		case sprmPFInnerTtp:
+			p.innerTtp = operand[0] != 0
+		case sprmPItap:
+			p.depth = int(int32(binary.LittleEndian.Uint32(operand)))
+			p.inTable = p.inTable || p.depth > 0
+		}
+	}
+}
+
+// operandSize follows the spra bits of the sprm, -1 when the operand does not fit
+func operandSize(sprm uint16, rest []byte) int {
+	switch sprm >> 13 {
+	case 0, 1:
+		return 1
+	case 2, 4, 5:
+		return 2
+	case 3:
+		return 4
+	case 7:
+		return 3
+	}
+
+	switch {
+	case sprm == sprmTDefTable && len(rest) >= 2:
+		return int(binary.LittleEndian.Uint16(rest)) + 1
+	case sprm == sprmPChgTabs && len(rest) >= 1 && rest[0] == 255:
+		// deleted tabs with close tolerances followed by added tabs with their descriptors
+		if len(rest) < 2 {
+			return -1
+		}
+
+		deleted := int(rest[1])
+		added := 2 + deleted*4
+		if added >= len(rest) {
+			return -1
+		}
+
+		return added + 1 + int(rest[added])*3
+	case len(rest) >= 1:
+		return 1 + int(rest[0])
+	}
+
+	return -1
+}
+
+// readDocTable rebuilds the first table from the text and the paragraph properties;
+// field codes are dropped in favour of their results and nested tables are read into their cell
+func readDocTable(text []docChar, paragraphs []docParagraph) (Table, error) {
+	out := Table{}
+	row := Row{}
+	cell := Cell{}
+	paragraph := []uint16{}
+
+	// true for the code part of every open field
+	fields := []bool{}
+
+	inCode := func() bool {
+		for _, code := range fields {
+			if code {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	lookup := func(offset uint32) docParagraph {
+		i := sort.Search(len(paragraphs), func(i int) bool {
+			return paragraphs[i].end > offset
+		})
+
+		if i < len(paragraphs) && paragraphs[i].start <= offset {
+			return paragraphs[i]
+		}
+
+		return docParagraph{}
+	}
+
+	for _, char := range text {
+		switch char.value {
+		case 0x13: // field begin
+			fields = append(fields, true)
+			continue
+		case 0x14: // field separator
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case 0x15: // field end
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+
+		if char.value != 0x0D && char.value != 0x07 {
+			if inCode() {
+				continue
+			}
+
+			switch char.value {
+			case 0x0B:
+				paragraph = append(paragraph, '\n')
+			case 0x1E:
+				paragraph = append(paragraph, '-')
+			case 0x01, 0x02, 0x03, 0x04, 0x05, 0x08, 0x0C, 0x1F:
+				// pictures, notes, annotations, drawings, page breaks and optional hyphens
+			default:
+				paragraph = append(paragraph, char.value)
+			}
+
+			continue
+		}
+
+		props := lookup(char.offset)
+		value := string(utf16.Decode(paragraph))
+		paragraph = paragraph[:0]
+
+		switch {
+		case !props.inTable && char.value == 0x0D:
+			// body text, after the rows it closes the first table
+			if len(out.Rows) > 0 {
+				return out, nil
+			}
+		case props.innerTtp:
+		case props.ttp:
+			out.Rows = append(out.Rows, row)
+			row = Row{}
+			cell = Cell{}
+		default:
+			cell.Paragraphs = append(cell.Paragraphs, value)
+
+			// the cells of a nested table are paragraphs of the outer cell
+			if char.value == 0x07 && !props.nested() {
+				row.Cells = append(row.Cells, cell)
+				cell = Cell{}
+			}
+		}
+	}
+
+	if len(out.Rows) == 0 {
+		return out, ErrNoTable
+	}
+
+	return out, nil
+}
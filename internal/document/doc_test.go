@@ -0,0 +1,183 @@
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+	"unicode/utf16"
+
+	"go-doc-parser/internal/cfb/cfbtest"
+)
+
+// docTestParagraph is the text of a paragraph with the properties of its mark:
+// 0 body, 1 in a table, 2 ends a row, 3 in a nested table and 4 ends a nested row
+type docTestParagraph struct {
+	text  string
+	props int
+}
+
+// buildDoc lays the paragraphs out in a Word 97-2003 file after a cp1252 intro paragraph
+func buildDoc(paragraphs []docTestParagraph) []byte {
+	le := binary.LittleEndian
+
+	const (
+		fkpPage  = 2
+		intro    = 1800 // a cp1252 piece
+		textBase = 2048 // a UTF-16 piece
+	)
+
+	word := make([]byte, 4096)
+	le.PutUint16(word[0:], 0xA5EC)
+	le.PutUint16(word[2:], 0xC1)
+	le.PutUint16(word[0x0A:], 0x0200)
+	le.PutUint16(word[32:], 14)
+	le.PutUint16(word[62:], 22)
+	le.PutUint16(word[152:], 93)
+
+	copy(word[intro:], "Intro\r")
+
+	text := []uint16{}
+	for _, p := range paragraphs {
+		text = append(text, utf16.Encode([]rune(p.text))...)
+	}
+
+	for i, u := range text {
+		le.PutUint16(word[textBase+i*2:], u)
+	}
+
+	le.PutUint32(word[64+3*4:], uint32(6+len(text)))
+
+	// PAPX FKP: the intro run, then a run per paragraph
+	page := word[fkpPage*512 : fkpPage*512+512]
+	runs := 1 + len(paragraphs)
+	page[511] = byte(runs)
+
+	le.PutUint32(page[0:], intro)
+	offset := uint32(textBase)
+	for i, p := range paragraphs {
+		le.PutUint32(page[(i+1)*4:], offset)
+		offset += uint32(len(utf16.Encode([]rune(p.text))) * 2)
+	}
+	le.PutUint32(page[runs*4:], offset)
+
+	// in table: cb 3 for istd and sprmPFInTable, row end: cb 0 then 4 words for istd, sprmPFInTable and sprmPFTtp
+	copy(page[400:], []byte{3, 0, 0, 0x16, 0x24, 1})
+	copy(page[420:], []byte{0, 4, 0, 0, 0x16, 0x24, 1, 0x17, 0x24, 1})
+
+	// nested: istd, sprmPFInTable, sprmPItap 2 and sprmPFInnerTableCell, the row end adds sprmPFInnerTtp
+	copy(page[440:], []byte{0, 7, 0, 0, 0x16, 0x24, 1, 0x49, 0x66, 2, 0, 0, 0, 0x4B, 0x24, 1})
+	copy(page[460:], []byte{0, 9, 0, 0, 0x16, 0x24, 1, 0x49, 0x66, 2, 0, 0, 0, 0x4B, 0x24, 1, 0x4C, 0x24, 1})
+
+	for i, p := range paragraphs {
+		bx := 4*(runs+1) + 13*(i+1)
+		if p.props > 0 {
+			page[bx] = byte(190 + 10*p.props)
+		}
+	}
+
+	table := make([]byte, 4096)
+
+	// PlcBtePapx at 0: the covered range and the page
+	le.PutUint32(table[0:], intro)
+	le.PutUint32(table[4:], offset)
+	le.PutUint32(table[8:], fkpPage)
+
+	// the Clx at 16 with one property record to skip and two pieces
+	clx := []byte{0x01, 2, 0, 0xAA, 0xBB, 0x02}
+	plc := []byte{}
+	for _, cp := range []uint32{0, 6, uint32(6 + len(text))} {
+		plc = le.AppendUint32(plc, cp)
+	}
+	plc = append(plc, 0, 0)
+	plc = le.AppendUint32(plc, intro*2|0x40000000)
+	plc = append(plc, 0, 0, 0, 0)
+	plc = le.AppendUint32(plc, textBase)
+	plc = append(plc, 0, 0)
+	clx = le.AppendUint32(clx, uint32(len(plc)))
+	clx = append(clx, plc...)
+	copy(table[16:], clx)
+
+	blob := 154
+	le.PutUint32(word[blob+13*8:], 0)
+	le.PutUint32(word[blob+13*8+4:], 12)
+	le.PutUint32(word[blob+33*8:], 16)
+	le.PutUint32(word[blob+33*8+4:], uint32(len(clx)))
+
+	return cfbtest.Build([]string{"WordDocument", "1Table"}, [][]byte{word, table})
+}
+
+func TestReadDoc(t *testing.T) {
+	file := buildDoc([]docTestParagraph{
+		{"A\x07", 1},
+		{"Кодима\r", 1},
+		{"\x13 HYPERLINK x \x14Окни\x15\x07", 1},
+		{"\x07", 2},
+		{"C\x07", 1},
+		{"\x07", 1},
+		{"\x07", 2},
+		{"After\r", 0},
+	})
+
+	got, err := ReadDoc(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got.Rows))
+	}
+
+	first := got.Rows[0].Cells
+	if len(first) != 2 || first[0].Text() != "A" || len(first[1].Paragraphs) != 2 || first[1].Paragraphs[0] != "Кодима" || first[1].Paragraphs[1] != "Окни" {
+		t.Errorf("unexpected first row %q", first)
+	}
+
+	second := got.Rows[1].Cells
+	if len(second) != 2 || second[0].Text() != "C" || second[1].Text() != "" {
+		t.Errorf("unexpected second row %q", second)
+	}
+}
+
+func TestReadDocNested(t *testing.T) {
+	// the second cell holds a nested table of one row, its cells end with a cell mark and a paragraph mark
+	file := buildDoc([]docTestParagraph{
+		{"A\x07", 1},
+		{"Кодима\r", 1},
+		{"x\x07", 3},
+		{"y\r", 3},
+		{"\r", 4},
+		{"\x07", 1},
+		{"\x07", 2},
+		{"C\x07", 1},
+		{"D\x07", 1},
+		{"\x07", 2},
+	})
+
+	got, err := ReadDoc(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got.Rows))
+	}
+
+	first := got.Rows[0].Cells
+	if len(first) != 2 || first[0].Text() != "A" || !slices.Equal(first[1].Paragraphs, []string{"Кодима", "x", "y", ""}) {
+		t.Errorf("expected the nested table inside the second cell, got %q", first)
+	}
+
+	second := got.Rows[1].Cells
+	if len(second) != 2 || second[0].Text() != "C" || second[1].Text() != "D" {
+		t.Errorf("unexpected second row %q", second)
+	}
+}
+
+func TestReadPiecesMalformed(t *testing.T) {
+	// a property record of -3 bytes pointing back at itself
+	_, err := readPieces([]byte{0x01, 0xFD, 0xFF, 0x00, 0x00})
+	if err == nil {
+		t.Error("expected an error for a negative property record size")
+	}
+}
@@ -0,0 +1,23 @@
+package document
+
+import "strings"
+
+// Table is the format independent view of a report table that ParseTable reads
+type Table struct {
+	Rows []Row
+}
+
+type Row struct {
+	Cells   []Cell
+	Revised bool // the row contains tracked changes nobody accepted or rejected yet
+}
+
+// Cell keeps the paragraphs apart, since the second paragraph of a name cell is a hint
+type Cell struct {
+	Paragraphs []string
+}
+
+// Text joins the paragraphs of the cell with spaces
+func (c Cell) Text() string {
+	return strings.Join(c.Paragraphs, " ")
+}
@@ -0,0 +1,219 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNoTable is returned when a document has no table to read the records from
+var ErrNoTable = errors.New("no table found")
+
+const wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// ReadDocx reads the first table of a Word document from its document.xml,
+// text in tracked changes is kept or left out following the revisions mode
+func ReadDocx(r io.ReaderAt, size int64, revisions Revisions) (Table, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Table{}, err
+	}
+
+	content, err := archive.Open("word/document.xml")
+	if err != nil {
+		return Table{}, err
+	}
+
+	defer content.Close()
+
+	decoder := xml.NewDecoder(content)
+
+	// only tables directly in the body count, not the ones in text boxes
+	parents := []string{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return Table{}, ErrNoTable
+		}
+		if err != nil {
+			return Table{}, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space == wordNamespace && token.Name.Local == "tbl" && len(parents) > 0 {
+				if parent := parents[len(parents)-1]; parent == "body" || parent == "sdtContent" {
+					return readDocxTable(decoder, revisions)
+				}
+			}
+
+			parents = append(parents, token.Name.Local)
+		case xml.EndElement:
+			if len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+		}
+	}
+}
+
+// docxMarks are the tracked changes of row, cell and paragraph mark properties
+type docxMarks struct {
+	Ins       *struct{} `xml:"ins"`
+	Del       *struct{} `xml:"del"`
+	CellIns   *struct{} `xml:"cellIns"`
+	CellDel   *struct{} `xml:"cellDel"`
+	CellMerge *struct{} `xml:"cellMerge"`
+	RPr       *struct {
+		Ins *struct{} `xml:"ins"`
+		Del *struct{} `xml:"del"`
+	} `xml:"rPr"`
+}
+
+func (m docxMarks) revised() bool {
+	return m.Ins != nil || m.Del != nil || m.CellIns != nil || m.CellDel != nil || m.CellMerge != nil || m.RPr != nil && (m.RPr.Ins != nil || m.RPr.Del != nil)
+}
+
+// readDocxTable reads the rows of a table, the decoder is positioned right after the table start;
+// every w:tc is a cell, nested tables are left out
+func readDocxTable(decoder *xml.Decoder, revisions Revisions) (Table, error) {
+	out := Table{}
+
+	var (
+		row  *Row
+		cell *Cell
+
+		// the paragraph being read, it carries over to the next one when its mark is hidden
+		paragraph = strings.Builder{}
+		joinNext  bool
+
+		// open w:ins or w:moveTo and w:del or w:moveFrom elements
+		inserted, deleted int
+
+		text       bool // inside w:t or w:delText
+		rowHidden  bool
+		rowRevised bool
+	)
+
+	visible := func() bool {
+		return !revisions.hidden(inserted > 0, deleted > 0)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return out, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space != wordNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "tbl", "rPr", "drawing", "pict", "object", "instrText", "delInstrText", "footnoteReference", "endnoteReference", "sdtPr":
+				err = decoder.Skip()
+			case "tr":
+				row = &Row{}
+				rowHidden, rowRevised = false, false
+			case "trPr":
+				marks := docxMarks{}
+				err = decoder.DecodeElement(&marks, &token)
+
+				rowRevised = rowRevised || marks.revised()
+				rowHidden = revisions.hidden(marks.Ins != nil, marks.Del != nil)
+			case "tc":
+				cell = &Cell{}
+			case "tcPr":
+				marks := docxMarks{}
+				err = decoder.DecodeElement(&marks, &token)
+
+				rowRevised = rowRevised || marks.revised()
+			case "p":
+				if !joinNext {
+					paragraph.Reset()
+				}
+
+				joinNext = false
+			case "pPr":
+				marks := docxMarks{}
+				err = decoder.DecodeElement(&marks, &token)
+
+				if marks.RPr != nil {
+					rowRevised = rowRevised || marks.revised()
+					joinNext = revisions.hidden(marks.RPr.Ins != nil, marks.RPr.Del != nil)
+				}
+			case "ins", "moveTo":
+				inserted++
+				rowRevised = true
+			case "del", "moveFrom":
+				deleted++
+				rowRevised = true
+			case "t", "delText":
+				text = true
+			case "tab":
+				if visible() {
+					paragraph.WriteByte('\t')
+				}
+			case "br", "cr":
+				if visible() {
+					paragraph.WriteByte('\n')
+				}
+			case "noBreakHyphen":
+				if visible() {
+					paragraph.WriteByte('-')
+				}
+			}
+		case xml.CharData:
+			if text && visible() {
+				paragraph.Write(token)
+			}
+		case xml.EndElement:
+			if token.Name.Space != wordNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "tbl":
+				return out, nil
+			case "tr":
+				if row != nil && !rowHidden {
+					row.Revised = rowRevised
+					out.Rows = append(out.Rows, *row)
+				}
+
+				row = nil
+			case "tc":
+				if cell == nil || row == nil {
+					continue
+				}
+
+				// the text of a paragraph whose mark was hidden stays in the cell
+				if joinNext {
+					cell.Paragraphs = append(cell.Paragraphs, paragraph.String())
+					joinNext = false
+				}
+
+				row.Cells = append(row.Cells, *cell)
+				cell = nil
+			case "p":
+				if cell != nil && !joinNext {
+					cell.Paragraphs = append(cell.Paragraphs, paragraph.String())
+				}
+			case "ins", "moveTo":
+				inserted--
+			case "del", "moveFrom":
+				deleted--
+			case "t", "delText":
+				text = false
+			}
+		}
+
+		if err != nil {
+			return out, err
+		}
+	}
+}
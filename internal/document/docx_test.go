@@ -0,0 +1,82 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+const docxContent = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:r><w:t>Звіт</w:t></w:r></w:p>
+<w:tbl>
+	<w:tr><w:tc><w:p><w:r><w:t>Час</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Підрозділ</w:t></w:r></w:p></w:tc></w:tr>
+	<w:tr>
+		<w:tc><w:p><w:r><w:t>19:10</w:t></w:r></w:p></w:tc>
+		<w:tc>
+			<w:p>
+				<w:pPr><w:rPr><w:del w:id="1" w:author="a"/></w:rPr></w:pPr>
+				<w:r><w:t xml:space="preserve">впс </w:t></w:r>
+				<w:del w:id="2" w:author="a"><w:r><w:delText>Окни</w:delText></w:r></w:del>
+				<w:ins w:id="3" w:author="a"><w:r><w:t>Кодима</w:t></w:r></w:ins>
+			</w:p>
+			<w:p><w:r><w:t>район</w:t></w:r></w:p>
+		</w:tc>
+	</w:tr>
+	<w:tr>
+		<w:trPr><w:del w:id="4" w:author="a"/></w:trPr>
+		<w:tc><w:p><w:r><w:t>20:00</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc>
+	</w:tr>
+</w:tbl>
+</w:body>
+</w:document>`
+
+func TestReadDocxRevisions(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	w, _ := archive.Create("word/document.xml")
+	w.Write([]byte(docxContent))
+	archive.Close()
+
+	tests := []struct {
+		revisions  Revisions
+		rows       int
+		paragraphs []string
+	}{
+		{AcceptRevisions, 2, []string{"впс Кодимарайон"}},
+		{RejectRevisions, 3, []string{"впс Окни", "район"}},
+		{AllRevisions, 3, []string{"впс ОкниКодима", "район"}},
+	}
+
+	for _, test := range tests {
+		table, err := ReadDocx(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), test.revisions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(table.Rows) != test.rows {
+			t.Errorf("%s: expected %d rows, got %d", test.revisions, test.rows, len(table.Rows))
+			continue
+		}
+
+		if table.Rows[0].Revised || !table.Rows[1].Revised {
+			t.Errorf("%s: only the second row should be revised", test.revisions)
+		}
+
+		name := table.Rows[1].Cells[1].Paragraphs
+		if len(name) != len(test.paragraphs) || name[0] != test.paragraphs[0] || name[len(name)-1] != test.paragraphs[len(name)-1] {
+			t.Errorf("%s: unexpected name cell %q", test.revisions, name)
+		}
+	}
+}
+
+func TestParseRevisions(t *testing.T) {
+	for name, expected := range map[string]Revisions{"": AcceptRevisions, "reject": RejectRevisions, "original": RejectRevisions, "all": AllRevisions} {
+		revisions, err := ParseRevisions(name)
+		if err != nil || revisions != expected {
+			t.Errorf("%q: expected %s, got %s %v", name, expected, revisions, err)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	tableNamespace = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	textNamespace  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// ReadODT reads the first table of an OpenDocument text file from its content.xml;
+// covered cells keep the columns aligned and repeat the content of the cell spanning them from above,
+// text in tracked changes is kept or left out following the revisions mode
+func ReadODT(r io.ReaderAt, size int64, revisions Revisions) (Table, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Table{}, err
+	}
+
+	content, err := archive.Open("content.xml")
+	if err != nil {
+		return Table{}, err
+	}
+
+	defer content.Close()
+
+	decoder := xml.NewDecoder(content)
+
+	// the change log comes first in the body, the table only marks where the changes are
+	changes := map[string]odtChange{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return Table{}, ErrNoTable
+		}
+		if err != nil {
+			return Table{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		switch {
+		case ok && start.Name.Space == textNamespace && start.Name.Local == "tracked-changes":
+			changes, err = readODTChanges(decoder)
+			if err != nil {
+				return Table{}, err
+			}
+		case ok && start.Name.Space == tableNamespace && start.Name.Local == "table":
+			return readODTTable(decoder, odtRevisions{changes, revisions})
+		}
+	}
+}
+
+// odtSpan is a cell that spans rows below it
+type odtSpan struct {
+	rows int
+	cell Cell
+}
+
+func readODTTable(decoder *xml.Decoder, revisions odtRevisions) (Table, error) {
+	out := Table{}
+
+	// spans by column
+	spans := map[int]*odtSpan{}
+
+	var row *Row
+	repeatRow := 1
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return out, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space != tableNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "table":
+				// tables nested in cells are read by readODTCell, anything else is not part of the report
+				err = decoder.Skip()
+				if err != nil {
+					return out, err
+				}
+			case "table-row":
+				row = &Row{}
+				repeatRow = attrInt(token, tableNamespace, "number-rows-repeated", 1)
+			case "table-cell", "covered-table-cell":
+				if row == nil {
+					err = decoder.Skip()
+					if err != nil {
+						return out, err
+					}
+					continue
+				}
+
+				cell, revised, err := readODTCell(decoder, revisions)
+				if err != nil {
+					return out, err
+				}
+
+				row.Revised = row.Revised || revised
+
+				repeat := attrInt(token, tableNamespace, "number-columns-repeated", 1)
+
+				for range min(repeat, 1024) {
+					column := len(row.Cells)
+
+					if token.Name.Local == "covered-table-cell" {
+						if span := spans[column]; span != nil {
+							cell = span.cell
+						}
+					} else if rows := attrInt(token, tableNamespace, "number-rows-spanned", 1); rows > 1 {
+						spans[column] = &odtSpan{rows: rows, cell: cell}
+					}
+
+					row.Cells = append(row.Cells, cell)
+				}
+			}
+		case xml.EndElement:
+			if token.Name.Space != tableNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "table":
+				return out, nil
+			case "table-row":
+				if row == nil {
+					continue
+				}
+
+				// repeated rows are usually the empty tail of a table, keep it small
+				for range min(repeatRow, 1024) {
+					out.Rows = append(out.Rows, *row)
+				}
+
+				for column, span := range spans {
+					span.rows -= repeatRow
+					if span.rows <= 0 {
+						delete(spans, column)
+					}
+				}
+
+				row = nil
+			}
+		}
+	}
+}
+
+// odtChange is a changed region of the change log, deleted holds the paragraphs a deletion removed
+type odtChange struct {
+	inserted bool
+	deleted  []string
+}
+
+// odtRevisions applies the mode to the changes of the change log
+type odtRevisions struct {
+	changes map[string]odtChange
+	mode    Revisions
+}
+
+// readODTChanges reads the change log, the decoder is positioned right after its start
+func readODTChanges(decoder *xml.Decoder) (map[string]odtChange, error) {
+	out := map[string]odtChange{}
+	id := ""
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return out, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space != textNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "changed-region":
+				id = attr(token, "id")
+			case "insertion":
+				out[id] = odtChange{inserted: true}
+			case "deletion":
+				// the removed text is kept in the log, the author and the date are not part of it
+				deleted, _, err := readODTCell(decoder, odtRevisions{})
+				if err != nil {
+					return out, err
+				}
+
+				out[id] = odtChange{deleted: deleted.Paragraphs}
+			}
+		case xml.EndElement:
+			if token.Name.Space == textNamespace && token.Name.Local == "tracked-changes" {
+				return out, nil
+			}
+		}
+	}
+}
+
+// readODTCell collects the paragraphs of a cell, the decoder is positioned right after the cell start;
+// inserted text is marked by a change start and end, deleted text by a change pointing at the log,
+// revised tells whether the cell has tracked changes
+func readODTCell(decoder *xml.Decoder, revisions odtRevisions) (cell Cell, revised bool, err error) {
+	var paragraph *strings.Builder
+
+	depth := 0
+
+	// the insertion whose text is left out and whether the paragraph began inside it
+	hiding := ""
+	hiddenParagraph := false
+
+	write := func(text string) {
+		if paragraph != nil && hiding == "" {
+			paragraph.WriteString(text)
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return cell, revised, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+
+			if token.Name.Space != textNamespace {
+				continue
+			}
+
+			switch token.Name.Local {
+			case "p", "h":
+				if paragraph == nil {
+					paragraph = &strings.Builder{}
+					hiddenParagraph = hiding != ""
+				}
+			case "s":
+				write(strings.Repeat(" ", attrInt(token, textNamespace, "c", 1)))
+			case "tab":
+				write("\t")
+			case "line-break":
+				write("\n")
+			case "note", "tracked-changes":
+				// footnotes and the change log are not part of the cell text
+				err = decoder.Skip()
+				if err != nil {
+					return cell, revised, err
+				}
+				depth--
+			case "change-start":
+				revised = true
+
+				id := attr(token, "change-id")
+				if change := revisions.changes[id]; change.inserted && revisions.mode.hidden(true, false) {
+					hiding = id
+				}
+			case "change-end":
+				revised = true
+
+				if attr(token, "change-id") == hiding {
+					hiding = ""
+				}
+			case "change":
+				revised = true
+
+				change := revisions.changes[attr(token, "change-id")]
+				if len(change.deleted) > 0 && !revisions.mode.hidden(false, true) {
+					write(strings.Join(change.deleted, "\n"))
+				}
+			}
+		case xml.CharData:
+			write(string(token))
+		case xml.EndElement:
+			if depth == 0 {
+				return cell, revised, nil
+			}
+
+			depth--
+
+			if token.Name.Space == textNamespace && (token.Name.Local == "p" || token.Name.Local == "h") && paragraph != nil {
+				// a paragraph inserted as a whole goes with its insertion
+				if !hiddenParagraph || hiding == "" || paragraph.Len() > 0 {
+					cell.Paragraphs = append(cell.Paragraphs, paragraph.String())
+				}
+
+				paragraph = nil
+			}
+		}
+	}
+}
+
+func attrInt(start xml.StartElement, space, local string, fallback int) int {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			value, err := strconv.Atoi(attr.Value)
+			if err != nil || value < 1 {
+				return fallback
+			}
+
+			return value
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,135 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+)
+
+const odtContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+	xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:text>
+<text:p>Звіт</text:p>
+<table:table>
+	<table:table-header-rows>
+		<table:table-row>
+			<table:table-cell><text:p>Час</text:p></table:table-cell>
+			<table:table-cell><text:p>Підрозділ</text:p></table:table-cell>
+			<table:table-cell><text:p>Примітка</text:p></table:table-cell>
+		</table:table-row>
+	</table:table-header-rows>
+	<table:table-row>
+		<table:table-cell><text:p>19:10</text:p></table:table-cell>
+		<table:table-cell table:number-rows-spanned="2"><text:p>впс<text:s text:c="2"/>«Кодима»</text:p><text:p>район <text:span>н.п.</text:span> Окни</text:p></table:table-cell>
+		<table:table-cell><text:p>Затримано 2 особи</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row>
+		<table:table-cell><text:p>20:00</text:p></table:table-cell>
+		<table:covered-table-cell/>
+		<table:table-cell table:number-columns-repeated="1"/>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="2">
+		<table:table-cell table:number-columns-repeated="3"/>
+	</table:table-row>
+</table:table>
+</office:text></office:body>
+</office:document-content>`
+
+func TestReadODT(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	w, _ := archive.Create("content.xml")
+	w.Write([]byte(odtContent))
+	archive.Close()
+
+	table, err := ReadODT(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), AcceptRevisions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(table.Rows))
+	}
+
+	name := table.Rows[1].Cells[1]
+	if len(name.Paragraphs) != 2 || name.Paragraphs[0] != "впс  «Кодима»" || name.Paragraphs[1] != "район н.п. Окни" {
+		t.Errorf("unexpected name cell %q", name.Paragraphs)
+	}
+
+	if covered := table.Rows[2].Cells[1].Text(); covered != name.Text() {
+		t.Errorf("covered cell should repeat the spanning cell, got %q", covered)
+	}
+
+	for i, row := range table.Rows {
+		if len(row.Cells) != 3 {
+			t.Errorf("row %d has %d cells", i, len(row.Cells))
+		}
+	}
+}
+
+const odtRevisionsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+	xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	xmlns:dc="http://purl.org/dc/elements/1.1/">
+<office:body><office:text>
+<text:tracked-changes>
+	<text:changed-region text:id="ct1"><text:deletion><office:change-info><dc:creator>Черговий</dc:creator></office:change-info><text:p>Окни</text:p></text:deletion></text:changed-region>
+	<text:changed-region text:id="ct2"><text:insertion><office:change-info><dc:creator>Черговий</dc:creator></office:change-info></text:insertion></text:changed-region>
+	<text:changed-region text:id="ct3"><text:insertion><office:change-info><dc:creator>Черговий</dc:creator></office:change-info></text:insertion></text:changed-region>
+</text:tracked-changes>
+<table:table>
+	<table:table-row>
+		<table:table-cell><text:p>Час</text:p></table:table-cell>
+		<table:table-cell><text:p>Підрозділ</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row>
+		<table:table-cell><text:p>19:10</text:p></table:table-cell>
+		<table:table-cell>
+			<text:p>впс <text:change text:change-id="ct1"/><text:change-start text:change-id="ct2"/>Кодима<text:change-end text:change-id="ct2"/></text:p>
+			<text:change-start text:change-id="ct3"/><text:p>район</text:p><text:change-end text:change-id="ct3"/>
+		</table:table-cell>
+	</table:table-row>
+</table:table>
+</office:text></office:body>
+</office:document-content>`
+
+func TestReadODTRevisions(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	w, _ := archive.Create("content.xml")
+	w.Write([]byte(odtRevisionsContent))
+	archive.Close()
+
+	tests := []struct {
+		revisions  Revisions
+		paragraphs []string
+	}{
+		{AcceptRevisions, []string{"впс Кодима", "район"}},
+		{RejectRevisions, []string{"впс Окни"}},
+		{AllRevisions, []string{"впс ОкниКодима", "район"}},
+	}
+
+	for _, test := range tests {
+		table, err := ReadODT(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), test.revisions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(table.Rows) != 2 || table.Rows[0].Revised || !table.Rows[1].Revised {
+			t.Errorf("%s: expected 2 rows with only the second one revised", test.revisions)
+			continue
+		}
+
+		if name := table.Rows[1].Cells[1].Paragraphs; !slices.Equal(name, test.paragraphs) {
+			t.Errorf("%s: expected the name cell %q, got %q", test.revisions, test.paragraphs, name)
+		}
+	}
+}
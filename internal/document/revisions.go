@@ -0,0 +1,58 @@
+package document
+
+import "fmt"
+
+// Revisions tells how tracked changes are read
+type Revisions int
+
+const (
+	AcceptRevisions Revisions = iota // every change accepted, the text Word shows without markup
+	RejectRevisions                  // every change rejected, the text as it was before the changes like Word's "Original" view
+	AllRevisions                     // the revision marks ignored, deleted and inserted text alike, for reviewing what was changed
+)
+
+var revisionNames = []string{"accept", "reject", "all"}
+
+// RevisionNames lists the names ParseRevisions accepts
+func RevisionNames() []string {
+	return revisionNames
+}
+
+func (r Revisions) String() string {
+	if r < 0 || int(r) >= len(revisionNames) {
+		return fmt.Sprintf("Revisions(%d)", int(r))
+	}
+
+	return revisionNames[r]
+}
+
+// ParseRevisions reads a mode name, the empty name is the default accept;
+// "original" is Word's name for the text before the changes, the same as reject
+func ParseRevisions(name string) (Revisions, error) {
+	switch name {
+	case "":
+		return AcceptRevisions, nil
+	case "original":
+		return RejectRevisions, nil
+	}
+
+	for i, known := range revisionNames {
+		if name == known {
+			return Revisions(i), nil
+		}
+	}
+
+	return AcceptRevisions, fmt.Errorf("unknown revisions mode %q", name)
+}
+
+// hidden tells whether text in a revision of the kind is left out in the mode
+func (r Revisions) hidden(inserted, deleted bool) bool {
+	switch r {
+	case AcceptRevisions:
+		return deleted
+	case RejectRevisions:
+		return inserted
+	}
+
+	return false
+}
@@ -0,0 +1,365 @@
+package document
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ErrTooDeep is returned by ReadRTF when groups are nested deeper than allowed
+var ErrTooDeep = errors.New("the groups are nested too deep")
+
+// rtfSkipped are destinations whose text is not part of the document body
+var rtfSkipped = map[string]bool{
+	"annotation":         true,
+	"colorschememapping": true,
+	"colortbl":           true,
+	"datastore":          true,
+	"filetbl":            true,
+	"fldinst":            true,
+	"footer":             true,
+	"footerf":            true,
+	"footerl":            true,
+	"footerr":            true,
+	"footnote":           true,
+	"generator":          true,
+	"header":             true,
+	"headerf":            true,
+	"headerl":            true,
+	"headerr":            true,
+	"info":               true,
+	"latentstyles":       true,
+	"listoverridetable":  true,
+	"listtable":          true,
+	"nonesttables":       true,
+	"nonshppict":         true,
+	"object":             true,
+	"pict":               true,
+	"revtbl":             true,
+	"rsidtbl":            true,
+	"shp":                true,
+	"stylesheet":         true,
+	"themedata":          true,
+	"xmlnstbl":           true,
+}
+
+// rtfSymbols are control words that stand for a character
+var rtfSymbols = map[string]rune{
+	"tab":       '\t',
+	"line":      '\n',
+	"emdash":    '—',
+	"endash":    '–',
+	"lquote":    '‘',
+	"rquote":    '’',
+	"ldblquote": '“',
+	"rdblquote": '”',
+	"bullet":    '•',
+	"emspace":   ' ',
+	"enspace":   ' ',
+	"qmspace":   ' ',
+}
+
+type rtfDestination int
+
+const (
+	rtfBody rtfDestination = iota
+	rtfFonts
+	rtfSkip
+)
+
+// rtfState is what a group restores when it ends
+type rtfState struct {
+	destination rtfDestination
+	font        int
+	uc          int // characters that stand in for a \u character in readers without Unicode
+}
+
+type rtfReader struct {
+	data     []byte
+	pos      int
+	state    rtfState
+	stack    []rtfState
+	maxDepth int // groups open at the same time, zero for no limit
+	err      error
+
+	codePage  int         // \ansicpg of the document
+	fonts     map[int]int // code pages of the fonts with a Cyrillic charset
+	font      int         // the font being defined in the font table
+	fallbacks int         // stand in characters still to drop after \u
+
+	inTable    bool // \intbl since the last \pard
+	rowDefined bool // between \trowd and \row
+	merged     []bool
+	mergeNext  bool
+
+	paragraph []rune
+	cell      Cell
+	row       Row
+	out       Table
+	done      bool
+}
+
+// ReadRTF reads the first table of a Rich Text Format document:
+// rows end with \row, cells with \cell and paragraphs within cells with \par,
+// and vertically merged cells repeat the content of the cell above like covered cells of OpenDocument tables;
+// groups nested deeper than maxDepth fail with ErrTooDeep, zero means no limit
+func ReadRTF(r io.ReaderAt, size int64, maxDepth int) (Table, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return Table{}, err
+	}
+
+	reader := &rtfReader{
+		data:     data,
+		state:    rtfState{uc: 1},
+		codePage: 1252,
+		fonts:    map[int]int{},
+		maxDepth: maxDepth,
+	}
+
+	reader.read()
+
+	if reader.err != nil {
+		return Table{}, reader.err
+	}
+
+	if len(reader.out.Rows) == 0 {
+		return reader.out, ErrNoTable
+	}
+
+	return reader.out, nil
+}
+
+func (r *rtfReader) read() {
+	for r.pos < len(r.data) && !r.done {
+		c := r.data[r.pos]
+		r.pos++
+
+		switch c {
+		case '{':
+			if r.maxDepth > 0 && len(r.stack) >= r.maxDepth {
+				r.err = ErrTooDeep
+				return
+			}
+
+			r.stack = append(r.stack, r.state)
+		case '}':
+			if len(r.stack) > 0 {
+				r.state = r.stack[len(r.stack)-1]
+				r.stack = r.stack[:len(r.stack)-1]
+			}
+		case '\\':
+			r.control()
+		case '\r', '\n':
+		default:
+			r.text(r.decode(c))
+		}
+	}
+}
+
+// control reads a control word or symbol after the backslash
+func (r *rtfReader) control() {
+	if r.pos >= len(r.data) {
+		return
+	}
+
+	c := r.data[r.pos]
+
+	if !isLetter(c) {
+		r.pos++
+
+		switch c {
+		case '\'':
+			if r.pos+2 <= len(r.data) {
+				value, err := strconv.ParseUint(string(r.data[r.pos:r.pos+2]), 16, 8)
+				r.pos += 2
+				if err == nil {
+					r.text(r.decode(byte(value)))
+				}
+			}
+		case '\\', '{', '}':
+			r.text(rune(c))
+		case '~':
+			r.text(' ')
+		case '_':
+			r.text('-')
+		case '*':
+			r.state.destination = rtfSkip
+		case '\r', '\n':
+			r.word("par", 0)
+		}
+
+		return
+	}
+
+	start := r.pos
+	for r.pos < len(r.data) && isLetter(r.data[r.pos]) {
+		r.pos++
+	}
+
+	name := string(r.data[start:r.pos])
+
+	start = r.pos
+	if r.pos < len(r.data) && r.data[r.pos] == '-' {
+		r.pos++
+	}
+	for r.pos < len(r.data) && r.data[r.pos] >= '0' && r.data[r.pos] <= '9' {
+		r.pos++
+	}
+
+	param, err := strconv.Atoi(string(r.data[start:r.pos]))
+	if err != nil {
+		param = 0
+	}
+
+	// a space delimits the control word and is not part of the text
+	if r.pos < len(r.data) && r.data[r.pos] == ' ' {
+		r.pos++
+	}
+
+	r.word(name, param)
+}
+
+func (r *rtfReader) word(name string, param int) {
+	if name == "bin" {
+		// binary data would be read as control words and groups
+		r.pos = min(r.pos+max(param, 0), len(r.data))
+		return
+	}
+
+	switch r.state.destination {
+	case rtfSkip:
+		return
+	case rtfFonts:
+		switch name {
+		case "f":
+			r.font = param
+		case "fcharset":
+			if param == 204 {
+				r.fonts[r.font] = 1251
+			}
+		case "cpg":
+			r.fonts[r.font] = param
+		}
+
+		return
+	}
+
+	if rtfSkipped[name] {
+		r.state.destination = rtfSkip
+		return
+	}
+
+	if symbol, ok := rtfSymbols[name]; ok {
+		r.text(symbol)
+		return
+	}
+
+	switch name {
+	case "fonttbl":
+		r.state.destination = rtfFonts
+	case "ansicpg":
+		r.codePage = param
+	case "f":
+		r.state.font = param
+	case "uc":
+		r.state.uc = max(param, 0)
+	case "u":
+		if param < 0 {
+			param += 65536
+		}
+
+		r.text(rune(param))
+		r.fallbacks = r.state.uc
+	case "pard":
+		r.inTable = false
+	case "intbl":
+		r.inTable = true
+	case "par", "nestcell":
+		r.endParagraph()
+	case "cell":
+		r.endCell()
+	case "row":
+		r.endRow()
+	case "trowd":
+		r.rowDefined = true
+		r.merged = nil
+		r.mergeNext = false
+	case "clvmrg":
+		r.mergeNext = true
+	case "cellx":
+		r.merged = append(r.merged, r.mergeNext)
+		r.mergeNext = false
+	}
+}
+
+func (r *rtfReader) text(c rune) {
+	if r.state.destination != rtfBody {
+		return
+	}
+
+	if r.fallbacks > 0 {
+		r.fallbacks--
+		return
+	}
+
+	r.paragraph = append(r.paragraph, c)
+}
+
+// decode reads a byte in the code page of the current font or the document
+func (r *rtfReader) decode(b byte) rune {
+	codePage, ok := r.fonts[r.state.font]
+	if !ok {
+		codePage = r.codePage
+	}
+
+	if codePage == 1251 {
+		return cp1251(b)
+	}
+
+	return rune(cp1252(b))
+}
+
+func (r *rtfReader) endParagraph() {
+	text := string(r.paragraph)
+	r.paragraph = nil
+
+	if r.inTable || r.rowDefined {
+		r.cell.Paragraphs = append(r.cell.Paragraphs, text)
+		return
+	}
+
+	// body text after the rows closes the first table
+	if len(r.out.Rows) > 0 {
+		r.done = true
+	}
+}
+
+func (r *rtfReader) endCell() {
+	r.cell.Paragraphs = append(r.cell.Paragraphs, string(r.paragraph))
+	r.paragraph = nil
+
+	r.row.Cells = append(r.row.Cells, r.cell)
+	r.cell = Cell{}
+}
+
+func (r *rtfReader) endRow() {
+	if len(r.out.Rows) > 0 {
+		above := r.out.Rows[len(r.out.Rows)-1]
+
+		for i, merged := range r.merged {
+			if merged && i < len(r.row.Cells) && i < len(above.Cells) {
+				r.row.Cells[i] = above.Cells[i]
+			}
+		}
+	}
+
+	r.out.Rows = append(r.out.Rows, r.row)
+	r.row = Row{}
+	r.paragraph = nil
+	r.rowDefined = false
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
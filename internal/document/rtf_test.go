@@ -0,0 +1,61 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const rtfContent = `{\rtf1\ansi\ansicpg1251\deff0{\fonttbl{\f0\fnil\fcharset0 Arial;}{\f1\fnil\fcharset204 Times;}}
+{\*\generator Legacy 1.0;}{\info{\title \'c7\'e2\'b3\'f2}}
+\pard\f1 \'c7\'e2\'b3\'f2\par
+\trowd\cellx1000\cellx3000\cellx5000
+\pard\intbl \'d7\'e0\'f1\cell \'cf\'b3\'e4\'f0\'ee\'e7\'e4\'b3\'eb\cell \u1055?\u1088?\u1080?\u1084?\u1110?\u1090?\u1082?\u1072?\cell\row
+\trowd\cellx1000\clvmgf\cellx3000\cellx5000
+\pard\intbl 19:10\cell {\b \'e2\'ef\'f1} \'ab\'ca\'ee\'e4\'e8\'ec\'e0\'bb\par \'f0\'e0\'e9\'ee\'ed {\field{\*\fldinst HYPERLINK "x"}{\fldrslt \'ce\'ea\'ed\'e8}}\cell \cell\row
+\trowd\cellx1000\clvmrg\cellx3000\cellx5000
+\pard\intbl 20:00\cell \cell \'c7\'e0\'f2\'f0\'e8\'ec\'e0\'ed\'ee\line 2 \'ee\'f1\'ee\'e1\'e8\cell\row
+\pard \'ca\'b3\'ed\'e5\'f6\par
+\trowd\cellx1000\pard\intbl other\cell\row
+}`
+
+func TestReadRTF(t *testing.T) {
+	table, err := ReadRTF(bytes.NewReader([]byte(rtfContent)), int64(len(rtfContent)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(table.Rows))
+	}
+
+	header := table.Rows[0].Cells
+	if len(header) != 3 || header[0].Text() != "Час" || header[1].Text() != "Підрозділ" || header[2].Text() != "Примітка" {
+		t.Errorf("unexpected header %q", header)
+	}
+
+	name := table.Rows[1].Cells[1]
+	if len(name.Paragraphs) != 2 || name.Paragraphs[0] != "впс «Кодима»" || name.Paragraphs[1] != "район Окни" {
+		t.Errorf("unexpected name cell %q", name.Paragraphs)
+	}
+
+	last := table.Rows[2].Cells
+	if last[0].Text() != "20:00" || last[1].Text() != name.Text() || last[2].Text() != "Затримано\n2 особи" {
+		t.Errorf("unexpected last row %q", last)
+	}
+}
+
+func TestReadRTFDepth(t *testing.T) {
+	_, err := ReadRTF(bytes.NewReader([]byte(rtfContent)), int64(len(rtfContent)), 8)
+	if err != nil {
+		t.Errorf("the report is nested 4 groups deep at most, got %v", err)
+	}
+
+	deep := `{\rtf1` + strings.Repeat("{", 1<<20) + strings.Repeat("}", 1<<20) + "}"
+
+	_, err = ReadRTF(bytes.NewReader([]byte(deep)), int64(len(deep)), 256)
+	if !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep, got %v", err)
+	}
+}
@@ -0,0 +1,455 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxMaxColumns keeps sparse rows with far away cells small, wider sheets are cut
+const xlsxMaxColumns = 1024
+
+// ReadXLSX reads the first visible worksheet of an Excel workbook;
+// line breaks within a cell separate its paragraphs, times and dates are written like in Word reports,
+// and merged cells repeat their content in the rows they span like covered cells of OpenDocument tables
+func ReadXLSX(r io.ReaderAt, size int64) (Table, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Table{}, err
+	}
+
+	sheet, err := firstSheet(archive)
+	if err != nil {
+		return Table{}, err
+	}
+
+	shared, err := readSharedStrings(archive)
+	if err != nil {
+		return Table{}, err
+	}
+
+	styles, err := readStyles(archive)
+	if err != nil {
+		return Table{}, err
+	}
+
+	opened, err := archive.Open(sheet)
+	if err != nil {
+		return Table{}, err
+	}
+
+	defer opened.Close()
+
+	return readSheet(xml.NewDecoder(opened), shared, styles)
+}
+
+// xlsxRelationships is the part of workbook.xml.rels that locates the sheets
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		State string `xml:"state,attr"`
+		ID    string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// firstSheet finds the part of the first visible sheet through the workbook and its relationships
+func firstSheet(archive *zip.Reader) (string, error) {
+	workbook := xlsxWorkbook{}
+
+	err := unmarshalPart(archive, "xl/workbook.xml", &workbook)
+	if err != nil {
+		return "", err
+	}
+
+	relationships := xlsxRelationships{}
+
+	err = unmarshalPart(archive, "xl/_rels/workbook.xml.rels", &relationships)
+	if err != nil {
+		return "", err
+	}
+
+	for _, sheet := range workbook.Sheets {
+		if sheet.State == "hidden" || sheet.State == "veryHidden" {
+			continue
+		}
+
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID != sheet.ID {
+				continue
+			}
+
+			if strings.HasPrefix(relationship.Target, "/") {
+				return strings.TrimPrefix(relationship.Target, "/"), nil
+			}
+
+			return path.Join("xl", relationship.Target), nil
+		}
+	}
+
+	return "", ErrNoTable
+}
+
+func unmarshalPart(archive *zip.Reader, name string, v any) error {
+	opened, err := archive.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer opened.Close()
+
+	return xml.NewDecoder(opened).Decode(v)
+}
+
+// xlsxText is a string with optional rich text runs, phonetic runs are left out
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	out := strings.Builder{}
+	out.WriteString(t.T)
+
+	for _, run := range t.Runs {
+		out.WriteString(run.T)
+	}
+
+	return out.String()
+}
+
+// readSharedStrings reads the string table, workbooks without strings have none
+func readSharedStrings(archive *zip.Reader) ([]string, error) {
+	opened, err := archive.Open("xl/sharedStrings.xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer opened.Close()
+
+	out := []string{}
+
+	decoder := xml.NewDecoder(opened)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "si" {
+			continue
+		}
+
+		text := xlsxText{}
+
+		err = decoder.DecodeElement(&text, &start)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, text.String())
+	}
+}
+
+type numberKind int
+
+const (
+	plainNumber numberKind = iota
+	dateNumber
+	timeNumber
+)
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// readStyles tells for every cell style whether its numbers are dates or times
+func readStyles(archive *zip.Reader) ([]numberKind, error) {
+	styles := xlsxStyles{}
+
+	err := unmarshalPart(archive, "xl/styles.xml", &styles)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	codes := map[int]string{}
+	for _, format := range styles.NumFmts {
+		codes[format.ID] = format.Code
+	}
+
+	out := []numberKind{}
+
+	for _, xf := range styles.CellXfs {
+		code, custom := codes[xf.NumFmtID]
+
+		switch {
+		case custom:
+			out = append(out, formatKind(code))
+		case xf.NumFmtID >= 14 && xf.NumFmtID <= 17:
+			out = append(out, dateNumber)
+		case xf.NumFmtID >= 18 && xf.NumFmtID <= 22, xf.NumFmtID >= 45 && xf.NumFmtID <= 47:
+			out = append(out, timeNumber)
+		default:
+			out = append(out, plainNumber)
+		}
+	}
+
+	return out, nil
+}
+
+// formatKind reads a custom number format code, quoted literals, escapes and [colors] aside;
+// elapsed time like [h]:mm counts as time
+func formatKind(code string) numberKind {
+	letters := strings.Builder{}
+
+	quoted := false
+
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\':
+			i++
+		case c == '[':
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				end = len(code) - i
+			}
+
+			if section := strings.ToLower(code[i+1 : i+end]); strings.Trim(section, "hms") == "" {
+				letters.WriteString(section)
+			}
+
+			i += end
+		default:
+			letters.WriteByte(c | 0x20)
+		}
+	}
+
+	switch lower := letters.String(); {
+	case strings.ContainsAny(lower, "hs"):
+		return timeNumber
+	case strings.ContainsAny(lower, "dy"):
+		return dateNumber
+	}
+
+	return plainNumber
+}
+
+// excelEpoch is day zero of the 1900 date system, shifted for the leap day Excel invented in 1900
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// formatNumber writes times as 15:04 and dates as 02.01.2006, a time with a date keeps only the time
+func formatNumber(value string, kind numberKind) string {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || kind == plainNumber || number < 0 || number > 2958465 {
+		return value
+	}
+
+	days, fraction := math.Modf(number)
+
+	if kind == dateNumber {
+		return excelEpoch.AddDate(0, 0, int(days)).Format("02.01.2006")
+	}
+
+	minutes := int(math.Round(fraction*24*60)) % (24 * 60)
+
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Style  int      `xml:"s,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+func readSheet(decoder *xml.Decoder, shared []string, styles []numberKind) (Table, error) {
+	out := Table{}
+
+	// rows by their number in the sheet, for the merged cells
+	rows := map[int]int{}
+
+	var row *Row
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "row":
+				out.Rows = append(out.Rows, Row{})
+				row = &out.Rows[len(out.Rows)-1]
+
+				number, err := strconv.Atoi(attr(token, "r"))
+				if err != nil {
+					number = len(out.Rows)
+				}
+
+				rows[number] = len(out.Rows) - 1
+			case "c":
+				cell := xlsxCell{}
+
+				err = decoder.DecodeElement(&cell, &token)
+				if err != nil {
+					return out, err
+				}
+
+				if row == nil {
+					continue
+				}
+
+				column := len(row.Cells)
+				if cell.Ref != "" {
+					column, _ = cellRef(cell.Ref)
+				}
+
+				if column < len(row.Cells) || column >= xlsxMaxColumns {
+					continue
+				}
+
+				for len(row.Cells) < column {
+					row.Cells = append(row.Cells, Cell{})
+				}
+
+				row.Cells = append(row.Cells, splitCells([]string{cellValue(cell, shared, styles)})...)
+			case "mergeCell":
+				merge(out, rows, attr(token, "ref"))
+			}
+		case xml.EndElement:
+			if token.Name.Local == "row" {
+				row = nil
+			}
+		}
+	}
+
+	if len(out.Rows) == 0 {
+		return out, ErrNoTable
+	}
+
+	// trailing empty cells are left out of the sheet, the rows get the width of the header back
+	for i := range out.Rows {
+		for len(out.Rows[i].Cells) < len(out.Rows[0].Cells) {
+			out.Rows[i].Cells = append(out.Rows[i].Cells, Cell{})
+		}
+	}
+
+	return out, nil
+}
+
+func cellValue(cell xlsxCell, shared []string, styles []numberKind) string {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(cell.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+
+		return shared[i]
+	case "inlineStr":
+		return cell.Inline.String()
+	case "", "n":
+		if cell.Style >= 0 && cell.Style < len(styles) {
+			return formatNumber(cell.Value, styles[cell.Style])
+		}
+	}
+
+	return cell.Value
+}
+
+// merge repeats the top left cell of a merged range in the rows below it
+func merge(table Table, rows map[int]int, ref string) {
+	from, to, ok := strings.Cut(ref, ":")
+	if !ok {
+		return
+	}
+
+	column, top := cellRef(from)
+	_, bottom := cellRef(to)
+
+	first, ok := rows[top]
+	if !ok || column >= len(table.Rows[first].Cells) {
+		return
+	}
+
+	for number := top + 1; number <= bottom; number++ {
+		i, ok := rows[number]
+		if !ok {
+			continue
+		}
+
+		for len(table.Rows[i].Cells) <= column {
+			table.Rows[i].Cells = append(table.Rows[i].Cells, Cell{})
+		}
+
+		table.Rows[i].Cells[column] = table.Rows[first].Cells[column]
+	}
+}
+
+// cellRef splits a reference like AB12 into the zero based column and the row number
+func cellRef(ref string) (column, row int) {
+	i := 0
+
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		column = column*26 + int(ref[i]-'A'+1)
+
+		if column > xlsxMaxColumns {
+			return xlsxMaxColumns, 0
+		}
+	}
+
+	row, _ = strconv.Atoi(ref[i:])
+
+	return column - 1, row
+}
+
+func attr(start xml.StartElement, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+
+	return ""
+}
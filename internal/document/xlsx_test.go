@@ -0,0 +1,92 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+var xlsxParts = map[string]string{
+	"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Приховано" sheetId="1" state="hidden" r:id="rId1"/><sheet name="Звіт" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+	"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Час</t></si><si><r><t>впс </t></r><r><t>«Кодима»
+район Окни</t></r><rPh><t>x</t></rPh></si></sst>`,
+	"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts><numFmt numFmtId="164" formatCode="[Red]hh&quot;h&quot;mm"/><numFmt numFmtId="165" formatCode="dd.mm.yyyy"/></numFmts>
+<cellXfs><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="165"/><xf numFmtId="20"/></cellXfs></styleSheet>`,
+	"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>Примітка</t></is></c></row>
+<row r="2"><c r="A2" s="1"><v>0.79861111111111116</v></c><c r="B2" t="s"><v>1</v></c><c r="D2" s="2"><v>45580</v></c></row>
+<row r="3"><c r="A3" s="3"><v>45580.5</v></c><c r="B3"/></row>
+</sheetData><mergeCells><mergeCell ref="B2:B3"/></mergeCells></worksheet>`,
+}
+
+func packXLSX(parts map[string]string) (*bytes.Reader, int64) {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	for name, content := range parts {
+		w, _ := archive.Create(name)
+		w.Write([]byte(content))
+	}
+	archive.Close()
+
+	return bytes.NewReader(buffer.Bytes()), int64(buffer.Len())
+}
+
+func TestReadXLSX(t *testing.T) {
+	table, err := ReadXLSX(packXLSX(xlsxParts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(table.Rows))
+	}
+
+	header := table.Rows[0].Cells
+	if len(header) != 3 || header[0].Text() != "Час" || len(header[1].Paragraphs) != 0 || header[2].Text() != "Примітка" {
+		t.Errorf("unexpected header %q", header)
+	}
+
+	row := table.Rows[1].Cells
+	if row[0].Text() != "19:10" || len(row[1].Paragraphs) != 2 || row[1].Paragraphs[0] != "впс «Кодима»" || row[1].Paragraphs[1] != "район Окни" || row[3].Text() != "15.10.2024" {
+		t.Errorf("unexpected row %q", row)
+	}
+
+	merged := table.Rows[2].Cells
+	if merged[0].Text() != "12:00" || merged[1].Text() != row[1].Text() {
+		t.Errorf("unexpected merged row %q", merged)
+	}
+}
+
+func TestReadXLSXEmptyLastColumn(t *testing.T) {
+	parts := map[string]string{}
+	for name, content := range xlsxParts {
+		parts[name] = content
+	}
+
+	parts["xl/worksheets/sheet2.xml"] = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>№</t></is></c><c r="E1" t="inlineStr"><is><t>Початок</t></is></c><c r="F1" t="inlineStr"><is><t>Кінець</t></is></c><c r="G1" t="inlineStr"><is><t>Підрозділ</t></is></c><c r="I1" t="inlineStr"><is><t>Примітка</t></is></c></row>
+<row r="2"><c r="A2"><v>1</v></c><c r="F2" t="inlineStr"><is><t>19:10</t></is></c><c r="G2" t="inlineStr"><is><t>впс Кодима</t></is></c><c r="I2" t="inlineStr"><is><t>затримано</t></is></c></row>
+<row r="3"><c r="A3"><v>2</v></c><c r="F3" t="inlineStr"><is><t>20:00</t></is></c><c r="G3" t="inlineStr"><is><t>впс Кодима</t></is></c></row>
+</sheetData></worksheet>`
+
+	table, err := ReadXLSX(packXLSX(parts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, row := range table.Rows {
+		if len(row.Cells) != 9 {
+			t.Errorf("row %d has %d cells instead of the 9 of the header", i+1, len(row.Cells))
+		}
+	}
+
+	if len(table.Rows) == 3 && len(table.Rows[2].Cells) == 9 && len(table.Rows[2].Cells[8].Paragraphs) != 0 {
+		t.Errorf("unexpected comment %q", table.Rows[2].Cells[8])
+	}
+}
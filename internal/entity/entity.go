@@ -1,12 +1,18 @@
 package entity
 
+import "encoding/json"
+
 type Group struct {
 	ID
-	Events []Event
+	Location Point `json:",omitzero"` // where the post is, dictionary entries may carry it
+	Events   []Event
 }
 
 type Page struct {
-	Filename            string
+	Filename string
+	Folder   string   // folder of the report within the upload, one per unit
+	Columns  []string `json:",omitempty"` // attribute keys of the events in table order
+
 	SelectedSupergroups [][]Group
 	OtherGroups         []Group
 }
@@ -17,6 +23,16 @@ type Data struct {
 	AggregatedOther    []Group
 	AggregatedComments []Group
 	Summary            string
+	SummaryTemplate    string `json:",omitempty"` // the placeholders the summary was rendered from, the report page renders it again
+	Diagnostics        []Diagnostic
+	Explanations       []Explanation   `json:",omitempty"`
+	Basemap            json.RawMessage `json:",omitempty"` // GeoJSON with the border and the posts drawn under the map of the report page
+}
+
+// Diagnostic reports a problem with an input file that was skipped or only partly processed
+type Diagnostic struct {
+	Filename string
+	Message  string
 }
 
 type ID struct {
@@ -30,12 +46,96 @@ type ShortID struct {
 }
 
 type Event struct {
-	Start   uint64 // hour?
-	End     uint64 // hour?
-	Comment string
+	Start    uint64 // hour?
+	End      uint64 // hour?
+	Duration uint64 `json:",omitempty"` // minutes from the start to the end, zero when the start is unknown
+	Comment  string
+
+	// detained persons counted in the comment and the sentences they were read from,
+	// no sentences means the comment gives no count
+	Detained         int      `json:",omitempty"`
+	DetainedEvidence []string `json:",omitempty"`
+
+	Dropped []string `json:",omitempty"` // comment paragraphs left out as saying nothing was found
+
+	Tags []string `json:",omitempty"` // categories given by the tagging rules
+
+	Mentions []Mention `json:",omitempty"` // entities found in the comment
+
+	Positions []Point `json:",omitempty"` // coordinates written in the comment or the hint
+
+	// every cell of the source row keyed by its normalized column header
+	Attributes map[string]string `json:",omitempty"`
+
+	Provenance Provenance
+}
+
+// Point is a position in decimal degrees of WGS 84
+type Point struct {
+	Lat, Lon float64
+}
+
+// Mention is an entity found in a text such as a settlement or a vehicle plate
+type Mention struct {
+	Kind  string // settlement, plate, nationality, coordinates or persons
+	Text  string // as written
+	Value string // normalized, such as the listed settlement name or "lat,lon"
+	Start int    // offsets in runes
+	End   int
+}
+
+// Provenance points at the row an event was read from
+type Provenance struct {
+	File  string
+	Table int      // index of the table within the document, only the first one is read for now
+	Row   int      // number of the row within the table, the header is row 1
+	Cells []string // the text of every cell as read, paragraphs on separate lines
 }
 
 type Record struct {
 	ID
 	Event
 }
+
+// Pivot counts the events grouped by the values of some of their fields
+type Pivot struct {
+	By   []string
+	Rows []PivotRow
+}
+
+type PivotRow struct {
+	Values   []string // one for every field of By
+	Count    int
+	Duration uint64 // minutes, only events with a known start add to it
+	First    uint64 // earliest end hour
+	Last     uint64 // latest end hour
+}
+
+// Timeline counts the events by the hour they ended, overall and for every post
+type Timeline struct {
+	Hours [24]int
+	Posts []TimelinePost
+}
+
+// TimelinePost is a row of the heatmap, the known posts are named after their supergroup
+type TimelinePost struct {
+	Name  string
+	Hours [24]int
+}
+
+// Explanation tells how the name cell of a row was classified
+type Explanation struct {
+	File       string
+	Row        int
+	Cell       string   // the name cell as read
+	Tokens     []string // type prefixes matched by the name parser
+	ID         ID
+	Supergroup string      // the dictionary supergroup the row was counted in, empty for the other groups
+	Nearest    []Candidate // the closest dictionary entries, the nearest first
+}
+
+// Candidate is a dictionary entry with its edit distance from a parsed name
+type Candidate struct {
+	ID
+	Distance int
+}
@@ -0,0 +1,124 @@
+package extract
+
+import (
+	"fmt"
+	"go-doc-parser/internal/entity"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// format is the value of a coordinates mention
+func format(p entity.Point) string {
+	return fmt.Sprintf("%.6f,%.6f", p.Lat, p.Lon)
+}
+
+func valid(p entity.Point) bool {
+	return math.Abs(p.Lat) <= 90 && math.Abs(p.Lon) <= 180
+}
+
+// decimalPair is "47.123456, 29.123456" or "47,123456 29,123456", the decimal comma needs a space or a semicolon between
+var decimalPair = regexp.MustCompile(`(-?\d{1,2}\.\d{3,})\s*[,;/ ]\s*(-?\d{1,3}\.\d{3,})|(-?\d{1,2},\d{3,})\s*[;/ ]\s*(-?\d{1,3},\d{3,})`)
+
+// dms is one coordinate in degrees, minutes and optional seconds with an optional hemisphere,
+// 47°12'34.5"N or 47°12′ пн. ш.
+const dms = `(\d{1,3})\s*°\s*(\d{1,2}(?:[.,]\d+)?)\s*['′’]\s*(?:(\d{1,2}(?:[.,]\d+)?)\s*(?:"|″|”|''|′′))?\s*(N|S|E|W|пн\.?\s*ш\.?|пд\.?\s*ш\.?|сх\.?\s*д\.?|зх\.?\s*д\.?)?`
+
+var dmsPair = regexp.MustCompile(dms + `\s*[,;/]?\s*` + dms)
+
+// hemispheres of the Ukrainian abbreviations
+var hemispheres = map[string]string{"пн": "N", "пд": "S", "сх": "E", "зх": "W"}
+
+func number(s string) float64 {
+	value, _ := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	return value
+}
+
+// degrees turns the submatches of dms into decimal degrees and the hemisphere, the southern and western are negative
+func degrees(groups []string) (float64, string) {
+	value := number(groups[0]) + number(groups[1])/60 + number(groups[2])/3600
+
+	hemisphere := groups[3]
+	if len(hemisphere) > 1 {
+		hemisphere = hemispheres[string([]rune(hemisphere)[:2])]
+	}
+
+	if hemisphere == "S" || hemisphere == "W" {
+		value = -value
+	}
+
+	return value, hemisphere
+}
+
+type located struct {
+	span
+	point entity.Point
+}
+
+func locate(text string) (out []located) {
+	for _, match := range decimalPair.FindAllStringSubmatchIndex(text, -1) {
+		if !bounded(text, match[0], match[1]) {
+			continue
+		}
+
+		first, second := 2, 4
+		if match[2] < 0 {
+			first, second = 6, 8
+		}
+
+		p := entity.Point{Lat: number(text[match[first]:match[first+1]]), Lon: number(text[match[second]:match[second+1]])}
+		if valid(p) {
+			out = append(out, located{span{match[0], match[1], Coordinates, format(p)}, p})
+		}
+	}
+
+	for _, match := range dmsPair.FindAllStringSubmatchIndex(text, -1) {
+		groups := make([]string, 8)
+		for i := range groups {
+			if match[2+i*2] >= 0 {
+				groups[i] = text[match[2+i*2]:match[3+i*2]]
+			}
+		}
+
+		a, first := degrees(groups[:4])
+		b, second := degrees(groups[4:])
+
+		// the latitude comes first unless the hemispheres say otherwise
+		p := entity.Point{Lat: a, Lon: b}
+		if first == "E" || first == "W" || second == "N" || second == "S" {
+			p = entity.Point{Lat: b, Lon: a}
+		}
+
+		if valid(p) {
+			out = append(out, located{span{match[0], match[1], Coordinates, format(p)}, p})
+		}
+	}
+
+	out = append(out, grid(text)...)
+
+	slices.SortFunc(out, func(a, b located) int {
+		return a.start - b.start
+	})
+
+	return
+}
+
+func coordinates(text string) (out []span) {
+	for _, l := range locate(text) {
+		out = append(out, l.span)
+	}
+
+	return
+}
+
+// ParsePoints finds the coordinates written in a text: decimal degrees, degrees and minutes with optional seconds,
+// UTM and MGRS grid references
+func ParsePoints(text string) (out []entity.Point) {
+	for _, l := range locate(text) {
+		out = append(out, l.point)
+	}
+
+	return
+}
@@ -0,0 +1,70 @@
+// Package extract finds settlements, vehicle plates, nationalities, coordinates and person counts
+// in the free text of comments, offline with rules and a gazetteer
+package extract
+
+import (
+	"go-doc-parser/internal/entity"
+	"slices"
+	"unicode/utf8"
+)
+
+// the kinds of the mentions
+const (
+	Settlement  = "settlement"
+	Plate       = "plate"
+	Nationality = "nationality"
+	Coordinates = "coordinates"
+	Persons     = "persons"
+)
+
+// span is a mention found at byte offsets, it is converted to rune offsets once all are found
+type span struct {
+	start, end int
+	kind       string
+	value      string
+}
+
+// Mentions finds the entities of a text, the gazetteer may be nil;
+// mentions do not overlap, the earlier and then the longer one wins
+func Mentions(text string, gazetteer *Gazetteer) []entity.Mention {
+	spans := []span{}
+
+	spans = append(spans, coordinates(text)...)
+	spans = append(spans, plates(text)...)
+	spans = append(spans, nationalities(text)...)
+	spans = append(spans, settlements(text, gazetteer)...)
+	spans = append(spans, persons(text)...)
+
+	slices.SortStableFunc(spans, func(a, b span) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+
+		return (b.end - b.start) - (a.end - a.start)
+	})
+
+	out := []entity.Mention{}
+	end := 0
+
+	for _, s := range spans {
+		if s.start < end {
+			continue
+		}
+
+		out = append(out, entity.Mention{
+			Kind:  s.kind,
+			Text:  text[s.start:s.end],
+			Value: s.value,
+			Start: utf8.RuneCountInString(text[:s.start]),
+			End:   utf8.RuneCountInString(text[:s.end]),
+		})
+
+		end = s.end
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return out
+}
@@ -0,0 +1,67 @@
+package extract
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	gazetteer := NewGazetteer([]string{"Кодима", "Велика Михайлівка", "Окни"})
+
+	text := "Затримано 2 особи, громадяни Молдови, біля Кодими на авто ВН 1234 АК (AA1234BC), " +
+		"ще двоє молдован у Великій Михайлівці; с. Загнітків, 47.772100, 29.529600 та 47°46'12\"N 29°31'50\"E"
+
+	expected := []string{
+		"persons 2 особи 2",
+		"nationality Молдови Молдова",
+		"settlement Кодими Кодима",
+		"plate ВН 1234 АК ВН1234АК",
+		"plate AA1234BC АА1234ВС",
+		"persons двоє молдован 2",
+		"settlement Великій Михайлівці Велика Михайлівка",
+		"settlement Загнітків Загнітків",
+		"coordinates 47.772100, 29.529600 47.772100,29.529600",
+		"coordinates 47°46'12\"N 29°31'50\"E 47.770000,29.530556",
+	}
+
+	mentions := Mentions(text, gazetteer)
+
+	got := []string{}
+	for _, m := range mentions {
+		got = append(got, fmt.Sprintf("%s %s %s", m.Kind, m.Text, m.Value))
+
+		if string([]rune(text)[m.Start:m.End]) != m.Text {
+			t.Errorf("%q is not at %d-%d", m.Text, m.Start, m.End)
+		}
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, got)
+	}
+}
+
+func TestPersonsNegative(t *testing.T) {
+	for _, text := range []string{"вилучено 500 грн", "рух 2 груп", "о 19 гр. Іванова", "2 км від кордону"} {
+		for _, m := range Mentions(text, nil) {
+			if m.Kind == Persons {
+				t.Errorf("%q: unexpected persons mention %q", text, m.Text)
+			}
+		}
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	points := ParsePoints("точка 46°28′ пн. ш. 30°44′ сх. д., друга 46,4825; 30,7233")
+
+	if len(points) != 2 || fmt.Sprintf("%.3f %.3f", points[0].Lat, points[0].Lon) != "46.467 30.733" || format(points[1]) != "46.482500,30.723300" {
+		t.Errorf("unexpected points %v", points)
+	}
+}
+
+func TestParseGrid(t *testing.T) {
+	points := ParsePoints("MGRS 18S UJ 23487 06483, UTM 36U 321000 5591000 біля КПП")
+
+	if len(points) != 2 || fmt.Sprintf("%.4f %.4f", points[0].Lat, points[0].Lon) != "38.8895 -77.0352" || fmt.Sprintf("%.2f %.2f", points[1].Lat, points[1].Lon) != "50.44 30.48" {
+		t.Errorf("unexpected points %v", points)
+	}
+}
@@ -0,0 +1,98 @@
+package extract
+
+import (
+	"os"
+	"strings"
+)
+
+// Gazetteer holds the names of the settlements that are recognized in any case form
+type Gazetteer struct {
+	names map[string][][]string // the stems of every name keyed by the stem of its first word
+	canon map[string]string     // the name as listed keyed by its joined stems
+}
+
+// NewGazetteer indexes the settlement names
+func NewGazetteer(names []string) *Gazetteer {
+	g := &Gazetteer{names: map[string][][]string{}, canon: map[string]string{}}
+
+	for _, name := range names {
+		stems := []string{}
+		for _, w := range strings.Fields(name) {
+			stems = append(stems, stem(w))
+		}
+
+		if len(stems) == 0 {
+			continue
+		}
+
+		key := strings.Join(stems, " ")
+		if _, ok := g.canon[key]; ok {
+			continue
+		}
+
+		g.canon[key] = strings.Join(strings.Fields(name), " ")
+
+		for _, first := range stemForms(stems[0]) {
+			g.names[first] = append(g.names[first], stems)
+		}
+	}
+
+	return g
+}
+
+// LoadGazetteer reads the settlement names from a file, one per line, an empty path means none
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			names = append(names, line)
+		}
+	}
+
+	return NewGazetteer(names), nil
+}
+
+// match finds the longest listed name starting at the word, it returns the number of words and the listed name
+func (g *Gazetteer) match(ws []word) (int, string) {
+	if g == nil || !capitalized(ws[0].text) {
+		return 0, ""
+	}
+
+	best, name := 0, ""
+
+	// the stem of the first word is not known, every shorter prefix is tried as one
+	first := []rune(strings.ToLower(ws[0].text))
+
+	for length := len(first); length >= max(len(first)-3, 1); length-- {
+		for _, stems := range g.names[string(first[:length])] {
+			if len(stems) > len(ws) || len(stems) <= best {
+				continue
+			}
+
+			matched := true
+			for i, s := range stems {
+				if !inflected(ws[i].text, s) || i > 0 && !capitalized(ws[i].text) {
+					matched = false
+					break
+				}
+			}
+
+			if matched {
+				best, name = len(stems), g.canon[strings.Join(stems, " ")]
+			}
+		}
+	}
+
+	return best, name
+}
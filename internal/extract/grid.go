@@ -0,0 +1,155 @@
+package extract
+
+import (
+	"go-doc-parser/internal/entity"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the latitude bands of UTM from 80°S, 8° each
+const bands = "CDEFGHJKLMNPQRSTUVWX"
+
+// utm is a zone with its band, then an easting of 6 digits and a northing of 7: "35T 612345 5212345"
+var utm = regexp.MustCompile(`(\d{1,2})\s?([C-HJ-NP-X])\s+(\d{6})(?:[.,]\d+)?\s*(?:m|м)?\s*(?:E|Е)?\s*[,;]?\s*(\d{7})(?:[.,]\d+)?\s*(?:m|м)?\s*(?:N)?`)
+
+// mgrs is a zone with its band, the letters of the 100 km square and the digits of the easting and the northing,
+// joined or separated by spaces: "35TNN1234567890", "35T NN 12345 67890"
+var (
+	mgrsJoined = regexp.MustCompile(`(\d{1,2})([C-HJ-NP-X])([A-HJ-NP-Z])([A-HJ-NP-V])(\d{2,10})`)
+	mgrsSpaced = regexp.MustCompile(`(\d{1,2})\s?([C-HJ-NP-X])\s+([A-HJ-NP-Z])([A-HJ-NP-V])\s+(\d{1,5})\s+(\d{1,5})`)
+)
+
+// minNorthings are the northings where the bands start, the 100 km rows repeat every 2000 km
+var minNorthings = map[byte]float64{
+	'C': 1100000, 'D': 2000000, 'E': 2800000, 'F': 3700000, 'G': 4600000, 'H': 5500000, 'J': 6400000, 'K': 7300000,
+	'L': 8200000, 'M': 9100000, 'N': 0, 'P': 800000, 'Q': 1700000, 'R': 2600000, 'S': 3500000, 'T': 4400000,
+	'U': 5300000, 'V': 6200000, 'W': 7000000, 'X': 7900000,
+}
+
+// fromUTM converts a UTM position to latitude and longitude on WGS 84
+func fromUTM(zone int, northern bool, easting, northing float64) entity.Point {
+	const (
+		k0 = 0.9996
+		a  = 6378137.0
+		f  = 1 / 298.257223563
+	)
+
+	e2 := f * (2 - f)
+	ep2 := e2 / (1 - e2)
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+
+	x := easting - 500000
+	y := northing
+	if !northern {
+		y -= 10000000
+	}
+
+	mu := y / k0 / (a * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+
+	phi := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sin, cos, tan := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+
+	n := a / math.Sqrt(1-e2*sin*sin)
+	t := tan * tan
+	c := ep2 * cos * cos
+	r := a * (1 - e2) / math.Pow(1-e2*sin*sin, 1.5)
+	d := x / (n * k0)
+
+	lat := phi - (n*tan/r)*(d*d/2-
+		(5+3*t+10*c-4*c*c-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t+298*c+45*t*t-252*ep2-3*c*c)*math.Pow(d, 6)/720)
+
+	lon := (d - (1+2*t+c)*math.Pow(d, 3)/6 +
+		(5-2*c+28*t-3*c*c+8*ep2+24*t*t)*math.Pow(d, 5)/120) / cos
+
+	central := float64((zone-1)*6 - 180 + 3)
+
+	return entity.Point{Lat: lat * 180 / math.Pi, Lon: central + lon*180/math.Pi}
+}
+
+// fromMGRS converts a grid reference, the digits hold the easting and the northing within the 100 km square
+func fromMGRS(zone int, band, column, row byte, easting, northing string) (entity.Point, bool) {
+	if zone < 1 || zone > 60 || len(easting) != len(northing) {
+		return entity.Point{}, false
+	}
+
+	set := (zone-1)%3 + 1
+
+	// the column letters go A to H, J to R and S to Z in turn, the row letters start at F in the even zones
+	columns := []string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}[set-1]
+	col := strings.IndexByte(columns, column)
+	if col < 0 {
+		return entity.Point{}, false
+	}
+
+	const rows = "ABCDEFGHJKLMNPQRSTUV"
+
+	r := strings.IndexByte(rows, row)
+	if zone%2 == 0 {
+		r = (r - 5 + len(rows)) % len(rows)
+	}
+
+	scale := math.Pow(10, float64(5-len(easting)))
+	e, _ := strconv.ParseFloat(easting, 64)
+	n, _ := strconv.ParseFloat(northing, 64)
+
+	x := float64(col+1)*100000 + e*scale
+	y := float64(r)*100000 + n*scale
+
+	for y < minNorthings[band] {
+		y += 2000000
+	}
+
+	return fromUTM(zone, band >= 'N', x, y), true
+}
+
+func grid(text string) (out []located) {
+	add := func(start, end int, p entity.Point) {
+		// the optional units may leave the match ending in spaces
+		end = start + len(strings.TrimRightFunc(text[start:end], unicode.IsSpace))
+
+		if bounded(text, start, end) && valid(p) {
+			out = append(out, located{span{start, end, Coordinates, format(p)}, p})
+		}
+	}
+
+	for _, m := range utm.FindAllStringSubmatchIndex(text, -1) {
+		zone, _ := strconv.Atoi(text[m[2]:m[3]])
+		easting, _ := strconv.ParseFloat(text[m[6]:m[7]], 64)
+		northing, _ := strconv.ParseFloat(text[m[8]:m[9]], 64)
+
+		if zone >= 1 && zone <= 60 {
+			add(m[0], m[1], fromUTM(zone, text[m[4]] >= 'N', easting, northing))
+		}
+	}
+
+	for _, m := range mgrsJoined.FindAllStringSubmatchIndex(text, -1) {
+		digits := text[m[10]:m[11]]
+		if len(digits)%2 != 0 {
+			continue
+		}
+
+		zone, _ := strconv.Atoi(text[m[2]:m[3]])
+
+		if p, ok := fromMGRS(zone, text[m[4]], text[m[6]], text[m[8]], digits[:len(digits)/2], digits[len(digits)/2:]); ok {
+			add(m[0], m[1], p)
+		}
+	}
+
+	for _, m := range mgrsSpaced.FindAllStringSubmatchIndex(text, -1) {
+		zone, _ := strconv.Atoi(text[m[2]:m[3]])
+
+		if p, ok := fromMGRS(zone, text[m[4]], text[m[6]], text[m[8]], text[m[10]:m[11]], text[m[12]:m[13]]); ok {
+			add(m[0], m[1], p)
+		}
+	}
+
+	return
+}
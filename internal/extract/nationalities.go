@@ -0,0 +1,85 @@
+package extract
+
+import "strings"
+
+// countries lists the stems of the country names in the genitive, "громадянин Молдови",
+// and of the demonyms, "молдованин", with the name the mentions get
+var countries = []struct {
+	name  string
+	stems []string
+}{
+	{"Україна", []string{"україн"}},
+	{"Молдова", []string{"молдов", "молдаван", "молдован"}},
+	{"Румунія", []string{"румун"}},
+	{"Росія", []string{"росі", "росіян"}},
+	{"Білорусь", []string{"білорус"}},
+	{"Польща", []string{"польщ", "поляк", "полячк"}},
+	{"Угорщина", []string{"угорщин", "угорц", "угорк"}},
+	{"Словаччина", []string{"словаччин", "словак", "словачк"}},
+	{"Туреччина", []string{"туреччин", "турк", "турок", "туркен"}},
+	{"Грузія", []string{"грузі", "грузин"}},
+	{"Азербайджан", []string{"азербайджан"}},
+	{"Узбекистан", []string{"узбекистан", "узбек"}},
+	{"Таджикистан", []string{"таджикистан", "таджик"}},
+	{"Індія", []string{"інді", "індійц", "індієць"}},
+	{"Пакистан", []string{"пакистан"}},
+	{"Бангладеш", []string{"бангладеш"}},
+	{"Сирія", []string{"сирі", "сирієць", "сирійц"}},
+	{"Афганістан", []string{"афганістан", "афганц", "афганець"}},
+	{"Єгипет", []string{"єгипт", "єгиптян"}},
+	{"Марокко", []string{"марокко", "марокканц", "марокканець"}},
+	{"Китай", []string{"китаю", "китайц", "китаєць"}},
+	{"В'єтнам", []string{"в'єтнам", "в’єтнам"}},
+}
+
+// citizens introduce a country: "громадянин Молдови", "гр. Румунії"
+var citizens = []string{"громадян", "гр", "підданий", "піддан"}
+
+// nationalities finds the countries named after a word for citizen and the demonyms
+func nationalities(text string) (out []span) {
+	ws := words(text)
+
+	for i, w := range ws {
+		country := demonym(w.text)
+		if country == "" {
+			continue
+		}
+
+		// a country name only counts after a word for citizen, a demonym such as "румун" on its own;
+		// the word for citizen is left out, so a count such as "двоє громадян" can take it
+		if capitalized(w.text) && (i == 0 || !isCitizen(ws[i-1].text)) {
+			continue
+		}
+
+		out = append(out, span{w.start, w.end, Nationality, country})
+	}
+
+	return
+}
+
+// demonym gives the country a word names or is the people of, lower cased it is a demonym such as "румуни"
+func demonym(word string) string {
+	lower := strings.ToLower(word)
+
+	for _, c := range countries {
+		for _, s := range c.stems {
+			if strings.HasPrefix(lower, s) {
+				return c.name
+			}
+		}
+	}
+
+	return ""
+}
+
+func isCitizen(word string) bool {
+	word = strings.ToLower(word)
+
+	for _, c := range citizens {
+		if word == c || len(c) > 4 && strings.HasPrefix(word, c) {
+			return true
+		}
+	}
+
+	return false
+}
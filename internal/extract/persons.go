@@ -0,0 +1,23 @@
+package extract
+
+import (
+	"go-doc-parser/internal/parser"
+	"strconv"
+)
+
+// persons finds the counts of people such as "3 особи", "двоє громадян" or "п'ятеро молдован"
+func persons(text string) (out []span) {
+	ws := words(text)
+
+	for i := 0; i+1 < len(ws); i++ {
+		n, ok := parser.Numeral(ws[i].text)
+		// "гр." is not a person noun, before a name it is a title and not a count
+		if !ok || !parser.IsPerson(ws[i+1].text) && demonym(ws[i+1].text) == "" {
+			continue
+		}
+
+		out = append(out, span{ws[i].start, ws[i+1].end, Persons, strconv.Itoa(n)})
+	}
+
+	return
+}
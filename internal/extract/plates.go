@@ -0,0 +1,29 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+)
+
+// plateLetters are the letters of Ukrainian plates, written in Cyrillic or with the Latin letters that look the same
+const plateLetters = `[АВСЕНІКМОРТХABCEHIKMOPTX]`
+
+var plate = regexp.MustCompile(plateLetters + `{2}[ -]?\d{4}[ -]?` + plateLetters + `{2}`)
+
+// latinPlate spells the Latin lookalikes in Cyrillic, so a plate has one value however it was typed
+var latinPlate = strings.NewReplacer("A", "А", "B", "В", "C", "С", "E", "Е", "H", "Н", "I", "І", "K", "К", "M", "М", "O", "О", "P", "Р", "T", "Т", "X", "Х")
+
+// plates finds Ukrainian vehicle plates such as "ВН 1234 АК"
+func plates(text string) (out []span) {
+	for _, match := range plate.FindAllStringIndex(text, -1) {
+		if !bounded(text, match[0], match[1]) {
+			continue
+		}
+
+		value := strings.NewReplacer(" ", "", "-", "").Replace(text[match[0]:match[1]])
+
+		out = append(out, span{match[0], match[1], Plate, latinPlate.Replace(value)})
+	}
+
+	return
+}
@@ -0,0 +1,44 @@
+package extract
+
+import "strings"
+
+// settlementPrefixes introduce a settlement name: "с. Окни", "смт Кодима", "м. Одеса"
+var settlementPrefixes = map[string]bool{
+	"с": true, "село": true, "селі": true, "села": true, "селища": true, "селище": true, "селищі": true,
+	"смт": true, "м": true, "місто": true, "місті": true, "міста": true, "н.п": true, "нп": true,
+}
+
+// settlements finds the names listed in the gazetteer and the capitalized names after a settlement prefix
+func settlements(text string, gazetteer *Gazetteer) (out []span) {
+	ws := words(text)
+
+	for i := 0; i < len(ws); i++ {
+		if n, name := gazetteer.match(ws[i:]); n > 0 {
+			out = append(out, span{ws[i].start, ws[i+n-1].end, Settlement, name})
+			i += n - 1
+			continue
+		}
+
+		prefix := strings.ToLower(ws[i].text)
+		if ws[i].end < len(text) && text[ws[i].end] == '.' {
+			// "н.п." is split into two words
+			if prefix == "н" && i+1 < len(ws) && strings.ToLower(ws[i+1].text) == "п" {
+				prefix, i = "н.п", i+1
+			}
+		}
+
+		if !settlementPrefixes[prefix] || i+1 >= len(ws) || !capitalized(ws[i+1].text) {
+			continue
+		}
+
+		// the name is one or two capitalized words right after the prefix
+		start, end := ws[i+1].start, ws[i+1].end
+		if i+2 < len(ws) && capitalized(ws[i+2].text) && strings.TrimSpace(text[end:ws[i+2].start]) == "" {
+			end = ws[i+2].end
+		}
+
+		out = append(out, span{start, end, Settlement, text[start:end]})
+	}
+
+	return
+}
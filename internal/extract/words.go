@@ -0,0 +1,103 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// word is a run of letters, digits and apostrophes at byte offsets of the text
+type word struct {
+	text       string
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == 'ʼ'
+}
+
+func words(text string) (out []word) {
+	start := -1
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start >= 0 {
+			out = append(out, word{text[start:i], start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		out = append(out, word{text[start:], start, len(text)})
+	}
+
+	return
+}
+
+func capitalized(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
+
+// bounded tells whether a match at the byte offsets is not a part of a longer word or number
+func bounded(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+
+	return true
+}
+
+// stem drops the case ending of a Ukrainian name, so "Кодима", "Кодими" and "Кодимі" share "Кодим"
+func stem(name string) string {
+	name = strings.ToLower(name)
+
+	for _, ending := range []string{"а", "я", "е", "и", "і", "о", "у", "ю", "ь", "й"} {
+		if trimmed, ok := strings.CutSuffix(name, ending); ok && utf8.RuneCountInString(trimmed) >= 3 {
+			return trimmed
+		}
+	}
+
+	return name
+}
+
+// alternations are the consonants that change before the dative and locative endings: "Михайлівка", "у Михайлівці"
+var alternations = map[rune]rune{'к': 'ц', 'г': 'з', 'х': 'с'}
+
+// stemForms lists the stem and, when its last consonant alternates, the changed stem
+func stemForms(s string) []string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return []string{s}
+	}
+
+	if changed, ok := alternations[runes[len(runes)-1]]; ok {
+		return []string{s, string(runes[:len(runes)-1]) + string(changed)}
+	}
+
+	return []string{s}
+}
+
+// inflected tells whether a word is a case form of a name with the stem
+func inflected(word, stem string) bool {
+	word = strings.ToLower(word)
+
+	for _, s := range stemForms(stem) {
+		if strings.HasPrefix(word, s) && utf8.RuneCountInString(word)-utf8.RuneCountInString(s) <= 3 {
+			return true
+		}
+	}
+
+	return false
+}
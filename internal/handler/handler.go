@@ -2,19 +2,21 @@ package handler
 
 import (
 	"archive/zip"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"go-doc-parser/internal/assets"
 	"go-doc-parser/internal/entity"
-	"html/template"
-	"io"
+	"go-doc-parser/internal/processor"
+	"go-doc-parser/internal/report"
 	"net/http"
+	"strings"
 )
 
-func Handler(process func([]*zip.File) entity.Data) func(w http.ResponseWriter, r *http.Request) {
+func Handler(process func(context.Context, []processor.Source) (entity.Data, error), limits processor.Limits) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "https://js-doc-parser.onrender.com")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		setCORSHeaders(w)
+		setSecurityHeaders(w)
 
 		// for k, v := range r.Header {
 		// 	for _, v := range v {
@@ -26,28 +28,118 @@ func Handler(process func([]*zip.File) entity.Data) func(w http.ResponseWriter,
 			return
 		}
 
-		body, err := io.ReadAll(r.Body)
+		sources, release, err := readArchive(w, r, limits)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Println("failed to read all:", err)
+			fail(w, err)
 			return
 		}
 
-		reader, err := zip.NewReader(bytes.NewReader(body), r.ContentLength)
+		defer release()
+
+		data, err := process(processor.WithPasswords(r.Context(), passwords(r)), sources)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			fail(w, err)
 			return
 		}
 
-		data := process(reader.File)
-
-		tpl, err := template.ParseFiles("template_new.gohtml")
+		err = report.HTML(w, data, false)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Println("failed to parse the templates:", err)
-			return
+			fmt.Println("failed to render the report:", err)
 		}
+	}
+}
+
+// PasswordHeader carries a password for the encrypted documents of an upload, it may be repeated
+const PasswordHeader = "X-Document-Password"
+
+func passwords(r *http.Request) []string {
+	return r.Header.Values(PasswordHeader)
+}
 
-		tpl.Execute(w, data)
+// readArchive spools the uploaded zip, to memory when small and to a temporary file otherwise,
+// and checks it against the limits; release removes the temporary file once the sources are processed
+func readArchive(w http.ResponseWriter, r *http.Request, limits processor.Limits) (sources []processor.Source, release func(), err error) {
+	if limits.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodySize)
 	}
+
+	body, size, release, err := processor.Spool(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources, err = processor.ZipSources(body, size, limits)
+	if errors.Is(err, zip.ErrFormat) {
+		err = &badRequest{err}
+	}
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return sources, release, nil
+}
+
+type badRequest struct {
+	error
+}
+
+// fail answers with the status that matches the error, so breached limits are reported to the client
+func fail(w http.ResponseWriter, err error) {
+	var maxBytes *http.MaxBytesError
+	var limit *processor.LimitError
+	var bad *badRequest
+
+	switch {
+	case errors.As(err, &maxBytes):
+		http.Error(w, "the upload exceeds the MaxBodySize limit", http.StatusRequestEntityTooLarge)
+	case errors.As(err, &limit):
+		status := http.StatusUnprocessableEntity
+		if strings.HasSuffix(limit.Limit, "Size") || limit.Limit == "MaxEntries" {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		http.Error(w, err.Error(), status)
+	case errors.As(err, &bad):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, context.Canceled):
+		// the client has gone away, nobody is listening
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	fmt.Println("failed to process the upload:", err)
+}
+
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "https://js-doc-parser.onrender.com")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+PasswordHeader)
+}
+
+// Static serves the vendored frontend assets, so the rendered page never needs external network access
+func Static() http.Handler {
+	files := http.StripPrefix("/static/", http.FileServerFS(assets.Static()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setSecurityHeaders(w)
+		files.ServeHTTP(w, r)
+	})
+}
+
+// contentSecurityPolicy only allows resources served by this binary;
+// inline style attributes are still used by the report scripts
+const contentSecurityPolicy = "default-src 'none'; " +
+	"script-src 'self'; " +
+	"style-src 'self'; " +
+	"style-src-attr 'unsafe-inline'; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"base-uri 'none'; " +
+	"form-action 'none'; " +
+	"frame-ancestors 'none'"
+
+func setSecurityHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 }
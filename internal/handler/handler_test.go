@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/jobs"
+	"go-doc-parser/internal/processor"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildZip(entries map[string][]byte) []byte {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	for name, data := range entries {
+		w, _ := archive.Create(name)
+		w.Write(data)
+	}
+	archive.Close()
+
+	return buffer.Bytes()
+}
+
+func TestHandlerLimits(t *testing.T) {
+	limits := processor.DefaultLimits
+	limits.MaxBodySize = 1 << 20
+	limits.MaxEntries = 3
+	limits.MaxDepth = 1
+
+	entries := map[string][]byte{}
+	for i := range 5 {
+		entries[fmt.Sprintf("%d.csv", i)] = []byte("a")
+	}
+
+	nested := buildZip(map[string][]byte{"unit.zip": buildZip(map[string][]byte{"report.csv": []byte("a")})})
+
+	tests := []struct {
+		name   string
+		body   []byte
+		status int
+	}{
+		{"body", bytes.Repeat([]byte("a"), 2<<20), http.StatusRequestEntityTooLarge},
+		{"entries", buildZip(entries), http.StatusRequestEntityTooLarge},
+		{"bomb", buildZip(map[string][]byte{"bomb.csv": make([]byte, 512<<10)}), http.StatusUnprocessableEntity},
+		{"depth", nested, http.StatusUnprocessableEntity},
+		{"not a zip", []byte("report"), http.StatusBadRequest},
+		{"fine", buildZip(map[string][]byte{"report.csv": []byte("a")}), http.StatusOK},
+	}
+
+	handler := Handler(processor.NewProcessor(nil, processor.Options{Limits: limits}), limits)
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.body)))
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected %d, got %d %q", test.name, test.status, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestFinishedJob(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	manager := jobs.NewManager(func(ctx context.Context, sources []processor.Source) (entity.Data, error) {
+		switch sources[0].Name {
+		case "fails":
+			return entity.Data{}, errors.New("no reports")
+		case "waits":
+			<-release
+		}
+
+		return entity.Data{}, nil
+	}, 3, 3, time.Hour)
+
+	submit := func(name string, state string) string {
+		job, err := manager.Submit([]processor.Source{{Name: name}}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		updates, cancel := job.Subscribe()
+		defer cancel()
+
+		for status := range updates {
+			if status.State == state {
+				break
+			}
+		}
+
+		return job.Status().ID
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"unknown", "missing", http.StatusNotFound},
+		{"done", submit("done", jobs.Done), http.StatusOK},
+		{"failed", submit("fails", jobs.Failed), http.StatusUnprocessableEntity},
+		{"running", submit("waits", jobs.Running), http.StatusConflict},
+	}
+
+	handler := JobExplain(manager)
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/jobs/"+test.id+"/explain", nil)
+		request.SetPathValue("id", test.id)
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected %d, got %d %q", test.name, test.status, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{"/static/van.js", http.StatusOK, "text/javascript"},
+		{"/static/report.js", http.StatusOK, "text/javascript"},
+		{"/static/report.css", http.StatusOK, "text/css"},
+		{"/static/emblem.svg", http.StatusOK, "image/svg+xml"},
+		{"/static/template_new.gohtml", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		Static().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected %d, got %d", test.path, test.status, recorder.Code)
+			continue
+		}
+
+		if test.status == http.StatusOK && (!strings.HasPrefix(recorder.Header().Get("Content-Type"), test.contentType) || recorder.Body.Len() == 0) {
+			t.Errorf("%s: expected %s content, got %q of %d bytes", test.path, test.contentType, recorder.Header().Get("Content-Type"), recorder.Body.Len())
+		}
+
+		// the policy only allows what this binary serves
+		expected := "default-src 'none'; script-src 'self'; style-src 'self'; style-src-attr 'unsafe-inline'; " +
+			"img-src 'self' data:; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+		if got := recorder.Header().Get("Content-Security-Policy"); got != expected {
+			t.Errorf("%s: unexpected Content-Security-Policy %q", test.path, got)
+		}
+
+		if got := recorder.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("%s: unexpected X-Content-Type-Options %q", test.path, got)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go-doc-parser/internal/jobs"
+	"go-doc-parser/internal/processor"
+	"go-doc-parser/internal/report"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SubmitJob queues the uploaded zip and answers right away with the job ID
+func SubmitJob(manager *jobs.Manager, limits processor.Limits) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+
+		sources, release, err := readArchive(w, r, limits)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		job, err := manager.Submit(sources, passwords(r), release)
+		if err != nil {
+			release()
+
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		id := job.Status().ID
+
+		w.Header().Set("Location", "/jobs/"+id)
+		writeJSON(w, http.StatusAccepted, map[string]string{"ID": id})
+	}
+}
+
+// JobStatus reports the state, the per file progress and, once done, the result of a job
+func JobStatus(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+
+		job, ok := manager.Get(r.PathValue("id"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job.Status())
+	}
+}
+
+// JobReport renders the report page for a finished job
+func JobReport(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+		setSecurityHeaders(w)
+
+		status, ok := finishedJob(manager, w, r)
+		if !ok {
+			return
+		}
+
+		err := report.HTML(w, *status.Result, false)
+		if err != nil {
+			fmt.Println("failed to render the report:", err)
+		}
+	}
+}
+
+// JobPivot groups the events of a finished job by the fields of the "by" parameter, such as ?by=name,hour;
+// the optional "cutoff" parameter drops the events that ended before the hour
+// and the repeatable "tag" parameter keeps the events carrying any of the tags
+func JobPivot(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+
+		status, ok := finishedJob(manager, w, r)
+		if !ok {
+			return
+		}
+
+		cutoff := uint64(0)
+		if value := r.URL.Query().Get("cutoff"); value != "" {
+			var err error
+
+			cutoff, err = strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				http.Error(w, "the cutoff must be an hour", http.StatusBadRequest)
+				return
+			}
+		}
+
+		result := processor.FilterTags(processor.Cutoff(*status.Result, cutoff), r.URL.Query()["tag"])
+
+		pivot, err := processor.NewPivot(result, processor.ParsePivotFields(r.URL.Query().Get("by")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, pivot)
+	}
+}
+
+// JobChart draws the result of a finished job as an SVG chart of the "kind" parameter, timeline or heatmap;
+// the optional "cutoff" and "tag" parameters filter the events like for JobPivot
+func JobChart(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+		setSecurityHeaders(w)
+
+		status, ok := finishedJob(manager, w, r)
+		if !ok {
+			return
+		}
+
+		kind := cmp.Or(r.URL.Query().Get("kind"), "timeline")
+		if !slices.Contains(report.ChartKinds, kind) {
+			http.Error(w, "the kind must be one of "+strings.Join(report.ChartKinds, ", "), http.StatusBadRequest)
+			return
+		}
+
+		cutoff, err := strconv.ParseUint(cmp.Or(r.URL.Query().Get("cutoff"), "0"), 10, 64)
+		if err != nil {
+			http.Error(w, "the cutoff must be an hour", http.StatusBadRequest)
+			return
+		}
+
+		result := processor.FilterTags(processor.Cutoff(*status.Result, cutoff), r.URL.Query()["tag"])
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+
+		err = report.WriteChart(w, processor.NewTimeline(result), kind)
+		if err != nil {
+			fmt.Println("failed to draw the chart:", err)
+		}
+	}
+}
+
+// JobExport writes the result of a finished job in the format of the "format" parameter, such as ?format=geojson;
+// the optional "cutoff" parameter drops the events that ended before the hour
+func JobExport(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+		setSecurityHeaders(w)
+
+		status, ok := finishedJob(manager, w, r)
+		if !ok {
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if !slices.Contains(report.Formats, format) {
+			http.Error(w, "the format must be one of "+strings.Join(report.Formats, ", "), http.StatusBadRequest)
+			return
+		}
+
+		cutoff, err := strconv.ParseUint(cmp.Or(r.URL.Query().Get("cutoff"), "0"), 10, 64)
+		if err != nil {
+			http.Error(w, "the cutoff must be an hour", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", report.ContentTypes[format])
+
+		err = report.Write(w, processor.Cutoff(*status.Result, cutoff), format)
+		if err != nil {
+			fmt.Println("failed to export the result:", err)
+		}
+	}
+}
+
+// JobExplain lists how every row of a finished job was classified
+func JobExplain(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+
+		status, ok := finishedJob(manager, w, r)
+		if !ok {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status.Result.Explanations)
+	}
+}
+
+// JobEvents streams the job status as Server-Sent Events until the job finishes
+func JobEvents(manager *jobs.Manager) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w)
+
+		job, ok := manager.Get(r.PathValue("id"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		updates, cancel := job.Subscribe()
+		defer cancel()
+
+		send := func(status jobs.Status) {
+			// the result can be large, it is fetched from the status endpoint instead
+			status.Result = nil
+
+			data, _ := json.Marshal(status)
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", status.State, data)
+			flusher.Flush()
+		}
+
+		last := jobs.Status{}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case status, ok := <-updates:
+				if !ok {
+					// intermediate updates may have been dropped, make sure the final state is sent
+					if last.State != jobs.Done && last.State != jobs.Failed {
+						send(job.Status())
+					}
+					return
+				}
+
+				send(status)
+				last = status
+			}
+		}
+	}
+}
+
+// finishedJob returns the status of the job in the path once it has a result, otherwise it writes
+// 404 for an unknown job, 422 with the error for a failed one or 409 for one that is still queued or running
+func finishedJob(manager *jobs.Manager, w http.ResponseWriter, r *http.Request) (jobs.Status, bool) {
+	job, ok := manager.Get(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return jobs.Status{}, false
+	}
+
+	status := job.Status()
+
+	switch {
+	case status.State == jobs.Failed:
+		// a failed job never gets a result, polling again does not help
+		http.Error(w, "the job has failed: "+status.Error, http.StatusUnprocessableEntity)
+		return jobs.Status{}, false
+	case status.Result == nil:
+		http.Error(w, "the job has not finished yet", http.StatusConflict)
+		return jobs.Status{}, false
+	}
+
+	return status, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		fmt.Println("failed to encode the response:", err)
+	}
+}
@@ -0,0 +1,278 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/processor"
+	"sync"
+	"time"
+)
+
+const (
+	Queued  = "queued"
+	Running = "running"
+	Done    = "done"
+	Failed  = "failed"
+)
+
+// ErrQueueFull is returned by Submit when every worker is busy and the queue has no room left
+var ErrQueueFull = errors.New("job queue is full")
+
+// Status is a snapshot of a job, safe to encode while the job keeps running
+type Status struct {
+	ID       string
+	State    string
+	Done     int
+	Total    int // the uploaded entries until the archives are expanded, then the reports that are parsed
+	Files    []FileStatus
+	Error    string       `json:",omitempty"`
+	Result   *entity.Data `json:",omitempty"`
+	Created  time.Time
+	Finished time.Time `json:",omitzero"`
+}
+
+// FileStatus reports the outcome of a single parsed file
+type FileStatus struct {
+	Filename string
+	Records  int
+	Error    string `json:",omitempty"`
+}
+
+// Job is a batch of sources processed in the background
+type Job struct {
+	mu          sync.Mutex
+	status      Status
+	sources     []processor.Source
+	passwords   []string
+	cleanup     func()
+	subscribers map[chan Status]bool
+}
+
+// Status returns the current snapshot
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.snapshot()
+}
+
+func (j *Job) snapshot() Status {
+	out := j.status
+	out.Files = append([]FileStatus{}, j.status.Files...)
+
+	return out
+}
+
+// Subscribe returns a channel with every status change, closed once the job has finished;
+// the current status is sent first
+func (j *Job) Subscribe() (updates <-chan Status, cancel func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ch := make(chan Status, 16)
+	ch <- j.snapshot()
+
+	if finished(j.status.State) {
+		close(ch)
+		return ch, func() {}
+	}
+
+	j.subscribers[ch] = true
+
+	return ch, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		if j.subscribers[ch] {
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// update changes the status and notifies the subscribers, slow subscribers miss intermediate updates
+func (j *Job) update(change func(status *Status)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	change(&j.status)
+
+	snapshot := j.snapshot()
+
+	for ch := range j.subscribers {
+		select {
+		case ch <- snapshot:
+		default:
+		}
+
+		if finished(snapshot.State) {
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func finished(state string) bool {
+	return state == Done || state == Failed
+}
+
+// Manager runs the submitted jobs on a fixed number of workers and forgets them TTL after they finish
+type Manager struct {
+	process func(ctx context.Context, sources []processor.Source) (entity.Data, error)
+	ttl     time.Duration
+
+	queue chan *Job
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func NewManager(process func(ctx context.Context, sources []processor.Source) (entity.Data, error), workers int, queueSize int, ttl time.Duration) *Manager {
+	m := &Manager{
+		process: process,
+		ttl:     ttl,
+		queue:   make(chan *Job, queueSize),
+		jobs:    map[string]*Job{},
+	}
+
+	for range workers {
+		go m.work()
+	}
+
+	go m.expire()
+
+	return m
+}
+
+// Submit queues the sources with the passwords of their encrypted documents,
+// cleanup is called once they are no longer needed
+func (m *Manager) Submit(sources []processor.Source, passwords []string, cleanup func()) (*Job, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	job := &Job{
+		status: Status{
+			ID:      hex.EncodeToString(id),
+			State:   Queued,
+			Total:   len(sources),
+			Files:   []FileStatus{},
+			Created: time.Now(),
+		},
+		sources:     sources,
+		passwords:   passwords,
+		cleanup:     cleanup,
+		subscribers: map[chan Status]bool{},
+	}
+
+	select {
+	case m.queue <- job:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	m.mu.Lock()
+	m.jobs[job.status.ID] = job
+	m.mu.Unlock()
+
+	return job, nil
+}
+
+// Get looks up a job that has not expired yet
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+
+	return job, ok
+}
+
+func (m *Manager) work() {
+	for job := range m.queue {
+		m.run(job)
+	}
+}
+
+func (m *Manager) run(job *Job) {
+	defer func() {
+		if job.cleanup != nil {
+			job.cleanup()
+		}
+
+		job.sources = nil
+		job.passwords = nil
+
+		if r := recover(); r != nil {
+			job.update(func(status *Status) {
+				status.State = Failed
+				status.Error = "processing failed"
+				status.Finished = time.Now()
+			})
+		}
+	}()
+
+	job.update(func(status *Status) {
+		status.State = Running
+	})
+
+	ctx := processor.WithProgress(context.Background(), func(done, total int, file processor.File) {
+		job.update(func(status *Status) {
+			status.Done = done
+			status.Total = total
+
+			fileStatus := FileStatus{Filename: file.Name, Records: len(file.Records)}
+			if file.Err != nil {
+				fileStatus.Error = file.Err.Error()
+			}
+
+			status.Files = append(status.Files, fileStatus)
+		})
+	})
+
+	data, err := m.process(processor.WithPasswords(ctx, job.passwords), job.sources)
+	if err != nil {
+		job.update(func(status *Status) {
+			status.State = Failed
+			status.Error = err.Error()
+			status.Finished = time.Now()
+		})
+
+		return
+	}
+
+	job.update(func(status *Status) {
+		status.State = Done
+		status.Total = status.Done // an upload without reports never reported its expanded count
+		status.Result = &data
+		status.Finished = time.Now()
+	})
+}
+
+// expire drops finished jobs once their results have been kept for the TTL
+func (m *Manager) expire() {
+	interval := m.ttl / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	for now := range time.Tick(interval) {
+		m.drop(now)
+	}
+}
+
+// drop forgets the jobs that finished more than the TTL before now
+func (m *Manager) drop(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, job := range m.jobs {
+		status := job.Status()
+
+		if finished(status.State) && now.Sub(status.Finished) > m.ttl {
+			delete(m.jobs, id)
+		}
+	}
+}
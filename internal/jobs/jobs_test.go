@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/processor"
+	"testing"
+	"time"
+)
+
+// blocking processes a batch once the channel is closed
+func blocking(release chan struct{}) func(ctx context.Context, sources []processor.Source) (entity.Data, error) {
+	return func(ctx context.Context, sources []processor.Source) (entity.Data, error) {
+		<-release
+		return entity.Data{Summary: "done"}, nil
+	}
+}
+
+// wait follows the job until it finishes and returns the last status sent
+func wait(t *testing.T, job *Job) (last Status) {
+	t.Helper()
+
+	updates, cancel := job.Subscribe()
+	defer cancel()
+
+	for status := range updates {
+		last = status
+	}
+
+	return
+}
+
+func TestQueueFull(t *testing.T) {
+	// no workers, so the queue is never drained
+	m := NewManager(blocking(nil), 0, 1, time.Hour)
+
+	job, err := m.Submit(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.Submit(nil, nil, nil)
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+
+	if _, ok := m.Get(job.Status().ID); !ok {
+		t.Error("the queued job should be known")
+	}
+
+	if len(m.jobs) != 1 {
+		t.Errorf("the rejected job should not be kept, got %d jobs", len(m.jobs))
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	release := make(chan struct{})
+	m := NewManager(blocking(release), 1, 1, time.Hour)
+
+	job, err := m.Submit(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates, cancel := job.Subscribe()
+
+	first := <-updates
+	if first.ID != job.Status().ID || (first.State != Queued && first.State != Running) {
+		t.Errorf("expected the current status first, got %+v", first)
+	}
+
+	close(release)
+
+	last := first
+	for status := range updates {
+		last = status
+	}
+
+	if last.State != Done || last.Result == nil || last.Result.Summary != "done" {
+		t.Errorf("expected the channel to close after the done status, got %+v", last)
+	}
+
+	// cancelling after the close, even twice, must not panic
+	cancel()
+	cancel()
+
+	// a subscriber of a finished job gets its status and a closed channel
+	if status := wait(t, job); status.State != Done {
+		t.Errorf("expected the done status, got %+v", status)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	m := NewManager(func(ctx context.Context, sources []processor.Source) (entity.Data, error) {
+		panic("broken report")
+	}, 1, 1, time.Hour)
+
+	cleaned := make(chan struct{})
+
+	job, err := m.Submit(nil, nil, func() { close(cleaned) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := wait(t, job)
+	if status.State != Failed || status.Error == "" || status.Finished.IsZero() {
+		t.Errorf("expected the panic to fail the job, got %+v", status)
+	}
+
+	select {
+	case <-cleaned:
+	default:
+		t.Error("expected cleanup to be called")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager(func(ctx context.Context, sources []processor.Source) (entity.Data, error) {
+		if len(sources) > 0 {
+			<-release
+		}
+
+		return entity.Data{}, nil
+	}, 2, 2, time.Hour)
+
+	finished, err := m.Submit(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := m.Submit([]processor.Source{{Name: "report.docx"}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := wait(t, finished)
+
+	m.drop(status.Finished.Add(time.Hour - time.Minute))
+
+	if _, ok := m.Get(status.ID); !ok {
+		t.Error("the job should be kept for the TTL")
+	}
+
+	m.drop(status.Finished.Add(time.Hour + time.Minute))
+
+	if _, ok := m.Get(status.ID); ok {
+		t.Error("the job should be dropped after the TTL")
+	}
+
+	if _, ok := m.Get(running.Status().ID); !ok {
+		t.Error("a job that has not finished should never be dropped")
+	}
+}
@@ -0,0 +1,401 @@
+// Package offcrypto decrypts password protected Office documents (MS-OFFCRYPTO),
+// which are compound files holding the EncryptionInfo and EncryptedPackage streams around the original zip package
+package offcrypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"unicode/utf16"
+
+	"go-doc-parser/internal/cfb"
+)
+
+var (
+	ErrNotEncrypted = errors.New("not an encrypted Office document")
+	ErrPassword     = errors.New("none of the passwords opens the document")
+)
+
+// maxSpinCount bounds the key derivation a hostile file can ask for to the 100000 Office writes,
+// every password is tried with it
+const maxSpinCount = 100_000
+
+// block keys of the agile encryption
+var (
+	verifierInputBlock = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	verifierHashBlock  = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	keyValueBlock      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+// Decrypt tries the passwords in order and returns the decrypted package, it never leaves memory
+func Decrypt(r io.ReaderAt, size int64, passwords []string) ([]byte, error) {
+	file, err := cfb.Open(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stream("EncryptionInfo")
+	if err != nil {
+		return nil, ErrNotEncrypted
+	}
+
+	encrypted, err := file.Stream("EncryptedPackage")
+	if err != nil {
+		return nil, ErrNotEncrypted
+	}
+
+	if len(info) < 8 || len(encrypted) < 8 {
+		return nil, errors.New("truncated encryption info")
+	}
+
+	major := binary.LittleEndian.Uint16(info)
+	minor := binary.LittleEndian.Uint16(info[2:])
+
+	var decrypt func(password string) ([]byte, error)
+
+	switch {
+	case major == 4 && minor == 4:
+		decrypt, err = agile(info[8:], encrypted)
+	case (major == 2 || major == 3 || major == 4) && minor == 2:
+		decrypt, err = standard(info[8:], encrypted)
+	default:
+		err = fmt.Errorf("unsupported encryption version %d.%d", major, minor)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, password := range passwords {
+		out, err := decrypt(password)
+		if errors.Is(err, ErrPassword) {
+			continue
+		}
+
+		return out, err
+	}
+
+	return nil, ErrPassword
+}
+
+// packageSize reads the size of the original package that precedes the encrypted data
+func packageSize(encrypted []byte) (int, error) {
+	size := binary.LittleEndian.Uint64(encrypted)
+	if size > uint64(len(encrypted)-8) {
+		return 0, errors.New("the encrypted package is shorter than its size")
+	}
+
+	return int(size), nil
+}
+
+// standard handles the AES variant of the standard encryption that Office 2007 writes
+func standard(info, encrypted []byte) (func(password string) ([]byte, error), error) {
+	if len(info) < 4 {
+		return nil, errors.New("truncated encryption info")
+	}
+
+	headerSize := int(binary.LittleEndian.Uint32(info))
+	if headerSize < 32 || 4+headerSize+4+16+16+4+32 > len(info) {
+		return nil, errors.New("truncated encryption header")
+	}
+
+	header := info[4 : 4+headerSize]
+
+	algorithm := binary.LittleEndian.Uint32(header[8:])
+	keyBits := int(binary.LittleEndian.Uint32(header[16:]))
+
+	// AES-128, AES-192 and AES-256
+	if algorithm < 0x660E || algorithm > 0x6610 || (keyBits != 128 && keyBits != 192 && keyBits != 256) {
+		return nil, fmt.Errorf("unsupported standard encryption algorithm %#x", algorithm)
+	}
+
+	verifier := info[4+headerSize:]
+
+	saltSize := int(binary.LittleEndian.Uint32(verifier))
+	if saltSize != 16 {
+		return nil, errors.New("unexpected salt size")
+	}
+
+	salt := verifier[4:20]
+	encryptedVerifier := verifier[20:36]
+	encryptedVerifierHash := verifier[40:72]
+
+	size, err := packageSize(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(password string) ([]byte, error) {
+		h := iterate(sha1.New, salt, password, 50000)
+		h = digest(sha1.New, h, []byte{0, 0, 0, 0})
+
+		// the hash is stretched to the key length like CryptDeriveKey does
+		x1 := make([]byte, 64)
+		x2 := make([]byte, 64)
+		for i := range 64 {
+			x1[i], x2[i] = 0x36, 0x5C
+			if i < len(h) {
+				x1[i] ^= h[i]
+				x2[i] ^= h[i]
+			}
+		}
+
+		key := append(digest(sha1.New, x1), digest(sha1.New, x2)...)[:keyBits/8]
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		plainVerifier := decryptECB(block, encryptedVerifier)
+		plainHash := decryptECB(block, encryptedVerifierHash)
+
+		if subtle.ConstantTimeCompare(digest(sha1.New, plainVerifier), plainHash[:sha1.Size]) != 1 {
+			return nil, ErrPassword
+		}
+
+		data := encrypted[8:]
+		data = data[:len(data)/aes.BlockSize*aes.BlockSize]
+		if len(data) < size {
+			return nil, errors.New("the encrypted package is shorter than its size")
+		}
+
+		return decryptECB(block, data)[:size], nil
+	}, nil
+}
+
+// agileInfo is the XML descriptor of the agile encryption that Office 2010 and later write
+type agileInfo struct {
+	KeyData       agileParameters `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string          `xml:"uri,attr"`
+		EncryptedKey agileParameters `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+type agileParameters struct {
+	SaltValue                  string `xml:"saltValue,attr"`
+	BlockSize                  int    `xml:"blockSize,attr"`
+	KeyBits                    int    `xml:"keyBits,attr"`
+	HashSize                   int    `xml:"hashSize,attr"`
+	CipherAlgorithm            string `xml:"cipherAlgorithm,attr"`
+	CipherChaining             string `xml:"cipherChaining,attr"`
+	HashAlgorithm              string `xml:"hashAlgorithm,attr"`
+	SpinCount                  int    `xml:"spinCount,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+const passwordEncryptor = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+
+func agile(info, encrypted []byte) (func(password string) ([]byte, error), error) {
+	descriptor := agileInfo{}
+
+	err := xml.Unmarshal(info, &descriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	var key *agileParameters
+	for i := range descriptor.KeyEncryptors {
+		if descriptor.KeyEncryptors[i].URI == passwordEncryptor {
+			key = &descriptor.KeyEncryptors[i].EncryptedKey
+		}
+	}
+
+	if key == nil {
+		return nil, errors.New("the document is not encrypted with a password")
+	}
+
+	for _, parameters := range []*agileParameters{key, &descriptor.KeyData} {
+		if parameters.CipherAlgorithm != "AES" || parameters.CipherChaining != "ChainingModeCBC" {
+			return nil, fmt.Errorf("unsupported cipher %s %s", parameters.CipherAlgorithm, parameters.CipherChaining)
+		}
+
+		if parameters.BlockSize != aes.BlockSize {
+			return nil, fmt.Errorf("unsupported block size %d", parameters.BlockSize)
+		}
+
+		if newHash(parameters.HashAlgorithm) == nil {
+			return nil, fmt.Errorf("unsupported hash algorithm %s", parameters.HashAlgorithm)
+		}
+	}
+
+	if key.SpinCount < 0 || key.SpinCount > maxSpinCount {
+		return nil, fmt.Errorf("unsupported spin count %d", key.SpinCount)
+	}
+
+	values := [][]byte{}
+	for _, value := range []string{key.SaltValue, key.EncryptedVerifierHashInput, key.EncryptedVerifierHashValue, key.EncryptedKeyValue, descriptor.KeyData.SaltValue} {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, decoded)
+	}
+
+	salt, verifierInput, verifierHash, keyValue, dataSalt := values[0], values[1], values[2], values[3], values[4]
+
+	size, err := packageSize(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	keyHash := newHash(key.HashAlgorithm)
+	dataHash := newHash(descriptor.KeyData.HashAlgorithm)
+
+	return func(password string) ([]byte, error) {
+		h := iterate(keyHash, salt, password, key.SpinCount)
+
+		decrypt := func(blockKey, value []byte) ([]byte, error) {
+			derived := fit(digest(keyHash, h, blockKey), key.KeyBits/8)
+			return decryptCBC(derived, fit(salt, aes.BlockSize), value)
+		}
+
+		input, err := decrypt(verifierInputBlock, verifierInput)
+		if err != nil {
+			return nil, err
+		}
+
+		expected, err := decrypt(verifierHashBlock, verifierHash)
+		if err != nil {
+			return nil, err
+		}
+
+		actual := digest(keyHash, input[:min(len(input), len(salt))])
+		if len(expected) < len(actual) || subtle.ConstantTimeCompare(actual, expected[:len(actual)]) != 1 {
+			return nil, ErrPassword
+		}
+
+		secret, err := decrypt(keyValueBlock, keyValue)
+		if err != nil {
+			return nil, err
+		}
+
+		secret = secret[:min(len(secret), descriptor.KeyData.KeyBits/8)]
+
+		// the package is encrypted in segments of 4096 bytes, each with its own initialization vector
+		out := make([]byte, 0, size)
+		data := encrypted[8:]
+
+		for segment := uint32(0); len(out) < size; segment++ {
+			chunk := data[:min(len(data), 4096)]
+			chunk = chunk[:len(chunk)/aes.BlockSize*aes.BlockSize]
+			if len(chunk) == 0 {
+				return nil, errors.New("the encrypted package is shorter than its size")
+			}
+
+			data = data[len(chunk):]
+
+			iv := fit(digest(dataHash, dataSalt, binary.LittleEndian.AppendUint32(nil, segment)), aes.BlockSize)
+
+			plain, err := decryptCBC(secret, iv, chunk)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, plain...)
+		}
+
+		return out[:size], nil
+	}, nil
+}
+
+func newHash(name string) func() hash.Hash {
+	switch name {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA384":
+		return sha512.New384
+	case "SHA512":
+		return sha512.New
+	case "MD5":
+		return md5.New
+	}
+
+	return nil
+}
+
+func digest(newHash func() hash.Hash, parts ...[]byte) []byte {
+	h := newHash()
+	for _, part := range parts {
+		h.Write(part)
+	}
+
+	return h.Sum(nil)
+}
+
+// iterate hashes the salted UTF-16 password and then rehashes it spinCount times with the iteration number
+func iterate(newHash func() hash.Hash, salt []byte, password string, spinCount int) []byte {
+	encoded := []byte{}
+	for _, unit := range utf16.Encode([]rune(password)) {
+		encoded = binary.LittleEndian.AppendUint16(encoded, unit)
+	}
+
+	h := digest(newHash, salt, encoded)
+
+	hasher := newHash()
+	counter := make([]byte, 4)
+
+	for i := range spinCount {
+		binary.LittleEndian.PutUint32(counter, uint32(i))
+
+		hasher.Reset()
+		hasher.Write(counter)
+		hasher.Write(h)
+		h = hasher.Sum(h[:0])
+	}
+
+	return h
+}
+
+// fit truncates or pads with 0x36 to the size
+func fit(value []byte, size int) []byte {
+	if len(value) >= size {
+		return value[:size]
+	}
+
+	return append(bytes.Clone(value), bytes.Repeat([]byte{0x36}, size-len(value))...)
+}
+
+func decryptECB(block cipher.Block, data []byte) []byte {
+	out := make([]byte, len(data))
+
+	for i := 0; i+aes.BlockSize <= len(data); i += aes.BlockSize {
+		block.Decrypt(out[i:], data[i:i+aes.BlockSize])
+	}
+
+	return out
+}
+
+func decryptCBC(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted data is not a whole number of blocks")
+	}
+
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+
+	return out, nil
+}
@@ -0,0 +1,150 @@
+package offcrypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-doc-parser/internal/cfb/cfbtest"
+)
+
+const password = "секрет"
+
+func encryptCBC(key, iv, data []byte) []byte {
+	block, _ := aes.NewCipher(key)
+
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, data)
+
+	return out
+}
+
+func pad(data []byte) []byte {
+	return append(bytes.Clone(data), make([]byte, (aes.BlockSize-len(data)%aes.BlockSize)%aes.BlockSize)...)
+}
+
+// agileDocument encrypts the package the way Office 2010 and later do
+func agileDocument(plain []byte, spinCount int) []byte {
+	salt := bytes.Repeat([]byte{1}, 16)
+	dataSalt := bytes.Repeat([]byte{2}, 16)
+	secret := bytes.Repeat([]byte{3}, 32)
+	verifierInput := bytes.Repeat([]byte{4}, 16)
+
+	h := iterate(sha512.New, salt, password, spinCount)
+	encrypt := func(blockKey, value []byte) string {
+		key := fit(digest(sha512.New, h, blockKey), 32)
+		return base64.StdEncoding.EncodeToString(encryptCBC(key, salt, pad(value)))
+	}
+
+	xml := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">
+<keyData saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"/>
+<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">
+<p:encryptedKey spinCount="%d" saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s" encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>
+</keyEncryptor></keyEncryptors></encryption>`,
+		base64.StdEncoding.EncodeToString(dataSalt),
+		spinCount,
+		base64.StdEncoding.EncodeToString(salt),
+		encrypt(verifierInputBlock, verifierInput),
+		encrypt(verifierHashBlock, digest(sha512.New, verifierInput)),
+		encrypt(keyValueBlock, secret),
+	)
+
+	info := append([]byte{4, 0, 4, 0, 0x40, 0, 0, 0}, xml...)
+
+	encrypted := binary.LittleEndian.AppendUint64(nil, uint64(len(plain)))
+	for segment := 0; segment*4096 < len(plain); segment++ {
+		chunk := pad(plain[segment*4096 : min(len(plain), (segment+1)*4096)])
+		iv := fit(digest(sha512.New, dataSalt, binary.LittleEndian.AppendUint32(nil, uint32(segment))), 16)
+		encrypted = append(encrypted, encryptCBC(secret, iv, chunk)...)
+	}
+
+	return cfbtest.Build([]string{"EncryptionInfo", "EncryptedPackage"}, [][]byte{info, encrypted})
+}
+
+// standardDocument encrypts the package the way Office 2007 does
+func standardDocument(plain []byte) []byte {
+	salt := bytes.Repeat([]byte{5}, 16)
+	verifier := bytes.Repeat([]byte{6}, 16)
+
+	h := digest(sha1.New, iterate(sha1.New, salt, password, 50000), []byte{0, 0, 0, 0})
+
+	x1 := bytes.Repeat([]byte{0x36}, 64)
+	for i := range h {
+		x1[i] ^= h[i]
+	}
+
+	block, _ := aes.NewCipher(digest(sha1.New, x1)[:16])
+	encrypt := func(data []byte) []byte {
+		out := make([]byte, len(data))
+		for i := 0; i < len(data); i += aes.BlockSize {
+			block.Encrypt(out[i:], data[i:i+aes.BlockSize])
+		}
+
+		return out
+	}
+
+	header := make([]byte, 32)
+	binary.LittleEndian.PutUint32(header[8:], 0x660E)
+	binary.LittleEndian.PutUint32(header[12:], 0x8004)
+	binary.LittleEndian.PutUint32(header[16:], 128)
+
+	info := []byte{3, 0, 2, 0, 0x24, 0, 0, 0}
+	info = binary.LittleEndian.AppendUint32(info, uint32(len(header)))
+	info = append(info, header...)
+	info = binary.LittleEndian.AppendUint32(info, 16)
+	info = append(info, salt...)
+	info = append(info, encrypt(verifier)...)
+	info = binary.LittleEndian.AppendUint32(info, 20)
+	info = append(info, encrypt(pad(digest(sha1.New, verifier)))...)
+
+	encrypted := binary.LittleEndian.AppendUint64(nil, uint64(len(plain)))
+	encrypted = append(encrypted, encrypt(pad(plain))...)
+
+	return cfbtest.Build([]string{"EncryptionInfo", "EncryptedPackage"}, [][]byte{info, encrypted})
+}
+
+func TestDecrypt(t *testing.T) {
+	plain := append([]byte("PK\x03\x04"), bytes.Repeat([]byte("report "), 1000)...)
+
+	for name, file := range map[string][]byte{"agile": agileDocument(plain, 1000), "standard": standardDocument(plain)} {
+		got, err := Decrypt(bytes.NewReader(file), int64(len(file)), []string{"wrong", password})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("%s: the decrypted package differs from the original", name)
+		}
+
+		_, err = Decrypt(bytes.NewReader(file), int64(len(file)), []string{"wrong"})
+		if !errors.Is(err, ErrPassword) {
+			t.Errorf("%s: expected ErrPassword, got %v", name, err)
+		}
+	}
+}
+
+func TestSpinCount(t *testing.T) {
+	plain := []byte("PK\x03\x04")
+
+	file := agileDocument(plain, maxSpinCount)
+	_, err := Decrypt(bytes.NewReader(file), int64(len(file)), []string{password})
+	if err != nil {
+		t.Errorf("the spin count Office writes is rejected: %v", err)
+	}
+
+	file = agileDocument(plain, maxSpinCount+1)
+	_, err = Decrypt(bytes.NewReader(file), int64(len(file)), []string{password})
+	if err == nil || !strings.Contains(err.Error(), "spin count") {
+		t.Errorf("expected the spin count to be rejected, got %v", err)
+	}
+}
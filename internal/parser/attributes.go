@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"go-doc-parser/internal/document"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HeaderKey normalizes a header cell into an attribute key:
+// lower case letters and digits with underscores in between, "Час (закінчення)" becomes "час_закінчення"
+func HeaderKey(header string) string {
+	words := strings.FieldsFunc(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "_")
+}
+
+// Columns names the columns of a table after its header row, columns without a usable header are column_N
+// and repeated headers get the column number appended
+func Columns(table document.Table) []string {
+	if len(table.Rows) == 0 {
+		return nil
+	}
+
+	columns := 0
+	for _, row := range table.Rows {
+		columns = max(columns, len(row.Cells))
+	}
+
+	header := table.Rows[0]
+
+	keys := make([]string, columns)
+	seen := map[string]bool{}
+
+	for i := range keys {
+		key := ""
+		if i < len(header.Cells) {
+			key = HeaderKey(header.Cells[i].Text())
+		}
+
+		if key == "" {
+			key = "column"
+		}
+
+		if key == "column" || seen[key] {
+			key += "_" + strconv.Itoa(i+1)
+		}
+
+		seen[key] = true
+		keys[i] = key
+	}
+
+	return keys
+}
+
+// attributes keeps every cell of a row under its column key, paragraphs stay on separate lines
+func attributes(keys []string, row document.Row) map[string]string {
+	out := make(map[string]string, len(row.Cells))
+
+	for i, cell := range row.Cells {
+		paragraphs := []string{}
+
+		for _, paragraph := range cell.Paragraphs {
+			paragraph = strings.Join(strings.Fields(paragraph), " ")
+
+			if len(paragraph) > 0 {
+				paragraphs = append(paragraphs, paragraph)
+			}
+		}
+
+		out[keys[i]] = strings.Join(paragraphs, "\n")
+	}
+
+	return out
+}
+
+// cells keeps the text of a row the way it was read, for tracing an event back to the document
+func cells(row document.Row) []string {
+	out := make([]string, len(row.Cells))
+
+	for i, cell := range row.Cells {
+		out[i] = strings.Join(cell.Paragraphs, "\n")
+	}
+
+	return out
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"go-doc-parser/internal/document"
+)
+
+func row(values ...string) document.Row {
+	out := document.Row{}
+	for _, value := range values {
+		out.Cells = append(out.Cells, document.Cell{Paragraphs: []string{value}})
+	}
+
+	return out
+}
+
+func TestColumns(t *testing.T) {
+	table := document.Table{Rows: []document.Row{
+		row("№ з/п", "Час (закінчення)", "", "Час"),
+		row("1", "19:10", "x", "y", "z"),
+	}}
+
+	expected := []string{"з_п", "час_закінчення", "column_3", "час", "column_5"}
+	if got := Columns(table); !slices.Equal(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	table.Rows[0] = row("Час", "час")
+	if got := Columns(table); !slices.Equal(got, []string{"час", "час_2", "column_3", "column_4", "column_5"}) {
+		t.Errorf("repeated headers are not told apart: %q", got)
+	}
+}
+
+func TestParseTableAttributes(t *testing.T) {
+	table := document.Table{Rows: []document.Row{
+		row("№", "Напрямок", "Тип", "Висота", "Початок", "Кінець", "Підрозділ", "Маршрут", "Примітка"),
+		row("1", "північ", "БпЛА", "300", "18:40", "19:10", "впс Кодима", "  Кодима -\tОкни ", ""),
+	}}
+
+	records := ParseTable("a.docx", table, DefaultNoFindings)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	if records[0].Start != 18 || records[0].End != 19 || records[0].Duration != 30 {
+		t.Errorf("unexpected times %d-%d, %d minutes", records[0].Start, records[0].End, records[0].Duration)
+	}
+
+	provenance := records[0].Provenance
+	if provenance.File != "a.docx" || provenance.Row != 2 || len(provenance.Cells) != 9 || provenance.Cells[7] != "  Кодима -\tОкни " {
+		t.Errorf("unexpected provenance %+v", provenance)
+	}
+
+	attributes := records[0].Attributes
+	if attributes["тип"] != "БпЛА" || attributes["маршрут"] != "Кодима - Окни" || attributes["примітка"] != "" {
+		t.Errorf("unexpected attributes %q", attributes)
+	}
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// numerals maps the case forms of the Ukrainian numerals, the collective "двоє" or "п'ятеро" included, to their values
+var numerals = map[string]int{}
+
+func init() {
+	forms := [][]string{
+		{"один", "одна", "одне", "одну", "одного", "одному", "одним", "одній", "одні", "одних"},
+		{"два", "дві", "двох", "двом", "двома", "двоє", "двоїх"},
+		{"три", "трьох", "трьом", "трьома", "троє", "троїх"},
+		{"чотири", "чотирьох", "чотирьом", "чотирма", "чотирьома", "четверо"},
+		{"п'ять", "п'яти", "п'ятьох", "п'ятьом", "п'ятьма", "п'ятьома", "п'ятеро"},
+		{"шість", "шести", "шістьох", "шістьом", "шістьма", "шістьома", "шестеро"},
+		{"сім", "семи", "сімох", "сімом", "сьома", "сімома", "семеро"},
+		{"вісім", "восьми", "вісьмох", "вісьмом", "вісьма", "вісьмома", "восьмеро"},
+		{"дев'ять", "дев'яти", "дев'ятьох", "дев'ятьом", "дев'ятьма", "дев'ятьома", "дев'ятеро"},
+		{"десять", "десяти", "десятьох", "десятьом", "десятьма", "десятьома", "десятеро"},
+	}
+
+	for i, words := range forms {
+		for _, word := range words {
+			numerals[word] = i + 1
+		}
+	}
+}
+
+// persons are the stems of the nouns that are counted, such a noun without a numeral is one person;
+// "гр." is too short to be a stem, it would match "грн" or "груп", see IsCitizen
+var persons = []string{"особ", "громадян", "порушник", "чолов", "жінк", "жінок", "нелегал", "мігрант", "іноземц", "неповнолітн", "юнак", "хлоп"}
+
+// apostrophes are spelled in several ways, the numerals are looked up with the plain one
+var apostrophes = strings.NewReplacer("’", "'", "ʼ", "'", "`", "'")
+
+// Numeral reads a token as a number of people, digits or a Ukrainian numeral in any case
+func Numeral(token string) (int, bool) {
+	if n, err := strconv.Atoi(token); err == nil {
+		return n, n > 0 && n < 1000
+	}
+
+	n, ok := numerals[apostrophes.Replace(strings.ToLower(token))]
+
+	return n, ok
+}
+
+// IsPerson tells whether a token is a noun for people such as "особи" or "громадян"
+func IsPerson(token string) bool {
+	token = strings.ToLower(token)
+
+	for _, stem := range persons {
+		if strings.HasPrefix(token, stem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCitizen tells whether the token at i is "гр." shortening "громадянин" in front of a capitalized name such as "гр. Іванова"
+func IsCitizen(tokens []string, i int) bool {
+	if i+1 >= len(tokens) || strings.ToLower(strings.TrimRight(tokens[i], ".")) != "гр" {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(tokens[i+1])
+
+	return unicode.IsUpper(first)
+}
+
+// attached tells whether the numeral at i counts people: "3 особи", "двох громадян" or "троє невідомих осіб"
+func attached(tokens []string, i int) bool {
+	return i+1 < len(tokens) && IsPerson(tokens[i+1]) || i+2 < len(tokens) && IsPerson(tokens[i+2]) && !isNumber(tokens[i+1])
+}
+
+func isNumber(token string) bool {
+	_, ok := Numeral(strings.TrimRight(token, ".:"))
+	return ok
+}
+
+// abbreviations are the words shortened with a dot inside a sentence
+var abbreviations = map[string]bool{"гр": true, "р": true, "с": true, "м": true, "смт": true, "вул": true, "обл": true, "п": true}
+
+// sentences splits a comment at the sentence ends and the line breaks
+func sentences(text string) (out []string) {
+	start := 0
+	runes := []rune(text)
+
+	for i, r := range runes {
+		end := r == '\n' || r == ';' || r == '!' || r == '?'
+
+		// a dot ends a sentence unless it shortens a word such as "гр." or "с."
+		if r == '.' && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			from := i
+			for from > start && unicode.IsLetter(runes[from-1]) {
+				from--
+			}
+
+			end = !abbreviations[strings.ToLower(string(runes[from:i]))]
+		}
+
+		if end {
+			out = append(out, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+
+	if start < len(runes) {
+		out = append(out, string(runes[start:]))
+	}
+
+	return
+}
+
+// Detained reads how many persons a comment says were detained, together with the sentences that say it;
+// no sentences means the comment gives no count, a denial such as "затримань не було" counts zero
+func Detained(comment string) (count int, evidence []string) {
+	for _, sentence := range sentences(comment) {
+		lower := apostrophes.Replace(strings.ToLower(sentence))
+
+		// the case is kept to tell "гр." before a name from other abbreviations
+		tokens := strings.FieldsFunc(apostrophes.Replace(sentence), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != ':' && r != '.'
+		})
+
+		mention := -1
+		for i, token := range tokens {
+			if strings.HasPrefix(strings.ToLower(token), "затрим") {
+				mention = i
+				break
+			}
+		}
+
+		if mention < 0 {
+			continue
+		}
+
+		if mention > 0 && (strings.EqualFold(tokens[mention-1], "не") || strings.EqualFold(tokens[mention-1], "без")) || strings.Contains(lower, "затримань не") {
+			evidence = append(evidence, strings.TrimSpace(sentence))
+			continue
+		}
+
+		// only a numeral in front of a person noun is a count, "о 19 год" or "500 грн" are not,
+		// the one nearest to the mention wins
+		best, bestDistance, counted := 0, 0, false
+
+		for i, token := range tokens {
+			n, ok := Numeral(strings.TrimRight(token, ".:"))
+			if !ok || !attached(tokens, i) {
+				continue
+			}
+
+			distance := max(i-mention, mention-i)
+
+			if !counted || distance < bestDistance {
+				best, bestDistance, counted = n, distance, true
+			}
+		}
+
+		if !counted {
+			// "затримано порушника" or "затримано гр. Іванова" speaks of one person
+			for i := mention + 1; i < len(tokens); i++ {
+				if IsPerson(tokens[i]) || IsCitizen(tokens, i) {
+					best, counted = 1, true
+					break
+				}
+			}
+		}
+
+		if counted {
+			count += best
+			evidence = append(evidence, strings.TrimSpace(sentence))
+		}
+	}
+
+	return count, evidence
+}
@@ -0,0 +1,31 @@
+package parser
+
+import "testing"
+
+func TestDetained(t *testing.T) {
+	tests := []struct {
+		comment   string
+		count     int
+		sentences int
+	}{
+		{"ОПДК виявлено о 19:10 на відстані 2 км. Затримано 3 особи.", 3, 1},
+		{"Затримано двох громадян України", 2, 1},
+		{"затримали п’ятеро нелегалів; ще одного порушника затримано о 20:40", 6, 2},
+		{"Затримано гр. Іванова І.І. біля с. Окни", 1, 1},
+		{"Виявлено сліди, затримань не було", 0, 1},
+		{"Виявлено сліди 2 осіб", 0, 0},
+		{"Затримано.", 0, 0},
+		{"Затримано автомобіль з 500 грн", 0, 0},
+		{"Затримано о 19 год гр. Іванова", 1, 1},
+		{"Затримано порушника, який перевозив 20 пачок цигарок", 1, 1},
+		{"Затримано у 2 км від кордону гр. України", 1, 1},
+		{"Затримано рух 2 груп", 0, 0},
+	}
+
+	for _, test := range tests {
+		count, evidence := Detained(test.comment)
+		if count != test.count || len(evidence) != test.sentences {
+			t.Errorf("%q: expected %d in %d sentences, got %d in %q", test.comment, test.count, test.sentences, count, evidence)
+		}
+	}
+}
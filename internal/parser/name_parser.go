@@ -9,6 +9,7 @@ import (
 type NameParser struct {
 	In       []rune
 	Position int
+	Tokens   []string // type prefixes matched by ParseName
 }
 
 func (p *NameParser) MatchChar(options ...string) string {
@@ -96,6 +97,12 @@ func (p *NameParser) trimQuotes() (out []rune) {
 
 	out = p.In[p.Position:len(p.In)]
 
+	p.Position = len(p.In)
+
+	if len(out) < 1 {
+		return
+	}
+
 	r = out[len(out)-1]
 
 	// assume the whole input ends in quote
@@ -103,8 +110,6 @@ func (p *NameParser) trimQuotes() (out []rune) {
 		out = out[:len(out)-1]
 	}
 
-	p.Position = len(p.In)
-
 	return out
 }
 
@@ -141,7 +146,9 @@ func (p *NameParser) ParseName() entity.ShortID {
 
 	name := p.trimQuotes()
 
+	p.Tokens = t
+
 	// fmt.Printf("%s %d %c\n", string(n), p.Position, p.In[p.Position-1])
 
-	return entity.ShortID{strings.Join(t, " "), string(name)}
+	return entity.ShortID{Type: strings.Join(t, " "), Name: string(name)}
 }
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NoFindings recognizes the comment paragraphs that only say nothing was found,
+// they are dropped from the comments so they do not count as cases
+type NoFindings struct {
+	Phrases  []string         // compared with the paragraph, both normalized
+	Patterns []*regexp.Regexp // matched against the normalized paragraph, anchor them to match it whole
+}
+
+// DefaultNoFindings is used when no phrases are configured
+var DefaultNoFindings = NoFindings{
+	Phrases: []string{
+		"ОПДК не виявлено",
+		"не виявлено",
+		"порушень не виявлено",
+		"без ОПДК",
+	},
+}
+
+// normalizeFinding lower cases a paragraph, collapses the spaces and drops the punctuation around it,
+// so "ОПДК  не виявлено." and "опдк не виявлено" are the same
+func normalizeFinding(paragraph string) string {
+	paragraph = strings.Join(strings.Fields(strings.ToLower(paragraph)), " ")
+
+	return strings.TrimFunc(paragraph, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// Match tells whether the paragraph only says nothing was found
+func (f NoFindings) Match(paragraph string) bool {
+	paragraph = normalizeFinding(paragraph)
+	if paragraph == "" {
+		return false
+	}
+
+	for _, phrase := range f.Phrases {
+		if normalizeFinding(phrase) == paragraph {
+			return true
+		}
+	}
+
+	for _, pattern := range f.Patterns {
+		if pattern.MatchString(paragraph) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadNoFindings reads the phrases from a file, one per line; a line between slashes such as /^нічого.*$/
+// is a pattern and lines starting with # are comments; an empty path gives DefaultNoFindings
+func LoadNoFindings(path string) (NoFindings, error) {
+	if path == "" {
+		return DefaultNoFindings, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NoFindings{}, err
+	}
+
+	out := NoFindings{}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case len(line) > 1 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/"):
+			pattern, err := regexp.Compile(line[1 : len(line)-1])
+			if err != nil {
+				return NoFindings{}, fmt.Errorf("line %d: %w", i+1, err)
+			}
+
+			out.Patterns = append(out.Patterns, pattern)
+		default:
+			out.Phrases = append(out.Phrases, line)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNoFindings(t *testing.T) {
+	for _, paragraph := range []string{"ОПДК не виявлено", "ОПДК  не виявлено.", "опдк не виявлено;", "Не виявлено", "«Без ОПДК»"} {
+		if !DefaultNoFindings.Match(paragraph) {
+			t.Errorf("%q should be dropped", paragraph)
+		}
+	}
+
+	for _, paragraph := range []string{"", "ОПДК не виявлено, але знайдено сліди", "Затримано 2 особи"} {
+		if DefaultNoFindings.Match(paragraph) {
+			t.Errorf("%q should be kept", paragraph)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "phrases.txt")
+	os.WriteFile(path, []byte("# phrases\nнічого\n/^без (змін|подій)$/\n"), 0o600)
+
+	configured, err := LoadNoFindings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !configured.Match("Нічого.") || !configured.Match("Без подій") || configured.Match("ОПДК не виявлено") {
+		t.Errorf("the configured phrases replace the default ones: %+v", configured)
+	}
+}
@@ -1,58 +1,109 @@
 package parser
 
 import (
+	"go-doc-parser/internal/document"
 	"go-doc-parser/internal/entity"
 	"strconv"
 	"strings"
-
-	"github.com/fumiama/go-docx"
 )
 
-func ParseTable(tag string, table *docx.Table) (out []entity.Record) {
-	for _, row := range table.TableRows[1:] {
-		endTime := row.TableCells[5].Paragraphs[0].String()
-		if len(endTime) < 2 {
-			continue
-		}
+// clock reads the hour and the minute of a time cell such as "19:10" or "19.10", the minute may be missing
+func clock(cell document.Cell) (hour, minute uint64, ok bool) {
+	if len(cell.Paragraphs) < 1 {
+		return 0, 0, false
+	}
+
+	value := strings.TrimSpace(cell.Paragraphs[0])
+	if len(value) < 2 {
+		return 0, 0, false
+	}
 
-		// TODO: start time, minutes?
+	hour, err := strconv.ParseUint(value[:2], 10, 64)
+	if err != nil || hour > 24 {
+		return 0, 0, false
+	}
 
-		end, err := strconv.ParseUint(endTime[:2], 10, 64)
-		if err != nil {
-			// report problem
-			continue
+	if len(value) >= 5 && (value[2] == ':' || value[2] == '.') {
+		minute, err = strconv.ParseUint(value[3:5], 10, 64)
+		if err != nil || minute > 59 {
+			minute = 0
 		}
+	}
 
-		parts := []string{}
+	return hour, minute, true
+}
 
-		for _, paragraph := range row.TableCells[6].Paragraphs {
-			parts = append(parts, strings.Join(strings.Fields(paragraph.String()), " "))
-		}
+// NameColumn holds the post name, further paragraphs of the cell are the hint
+const NameColumn = 6
+
+// ParseID reads the name cell: the first paragraph is parsed into the type and the name, the rest is the hint;
+// the type prefixes that were matched are returned as well
+func ParseID(cell document.Cell) (entity.ID, []string) {
+	parts := []string{}
+
+	for _, paragraph := range cell.Paragraphs {
+		parts = append(parts, strings.Join(strings.Fields(paragraph), " "))
+	}
+
+	if len(parts) < 1 {
+		return entity.ID{}, nil
+	}
+
+	hint := ""
+
+	if len(parts) > 1 {
+		hint = strings.Join(parts[1:], " ")
+	}
 
-		item := parts[0]
+	parser := NameParser{
+		In: []rune(parts[0]),
+	}
+
+	shortID := parser.ParseName()
+
+	return entity.ID{ShortID: shortID, Hint: hint}, parser.Tokens
+}
+
+func ParseTable(tag string, table document.Table, noFindings NoFindings) (out []entity.Record) {
+	if len(table.Rows) < 2 {
+		return
+	}
 
-		hint := ""
+	keys := Columns(table)
 
-		if len(parts) > 1 {
-			hint = strings.Join(parts[1:], " ")
+	for i, row := range table.Rows[1:] {
+		// rows without the comment column or with empty time and name cells cannot be read
+		if len(row.Cells) < 9 || len(row.Cells[5].Paragraphs) < 1 || len(row.Cells[NameColumn].Paragraphs) < 1 {
+			continue
 		}
 
-		parser := NameParser{
-			In: []rune(item),
+		end, endMinute, ok := clock(row.Cells[5])
+		if !ok {
+			// report problem
+			continue
 		}
 
-		shortID := parser.ParseName()
+		// the start is optional, without it the duration stays unknown
+		start, startMinute, hasStart := clock(row.Cells[4])
 
-		paragraphs := []string{}
+		duration := uint64(0)
+		if hasStart {
+			// reports cover a day, a flight that ends before it starts crossed midnight
+			duration = (end*60 + endMinute + 24*60 - start*60 - startMinute) % (24 * 60)
+		}
+
+		id, _ := ParseID(row.Cells[NameColumn])
 
-		for _, p := range row.TableCells[8].Paragraphs {
+		paragraphs := []string{}
+		dropped := []string{}
 
-			paragraph := p.String()
+		for _, paragraph := range row.Cells[8].Paragraphs {
 
 			paragraph = strings.Join(strings.Fields(paragraph), " ")
 
-			// ???
-			if paragraph == "ОПДК не виявлено" {
+			// a paragraph saying nothing was found is not a case, it is kept aside
+			if noFindings.Match(paragraph) {
+				dropped = append(dropped, paragraph)
 				continue
 			}
 
@@ -61,15 +112,26 @@ func ParseTable(tag string, table *docx.Table) (out []entity.Record) {
 			}
 		}
 
+		comment := strings.Join(paragraphs, "\n")
+		detained, evidence := Detained(comment)
+
 		record := entity.Record{
-			ID: entity.ID{
-				shortID,
-				hint,
-			},
+			ID: id,
 			Event: entity.Event{
-				Start:   0,
-				End:     end,
-				Comment: strings.Join(paragraphs, "\n"),
+				Start:            start,
+				End:              end,
+				Duration:         duration,
+				Comment:          comment,
+				Detained:         detained,
+				DetainedEvidence: evidence,
+				Dropped:          dropped,
+				Attributes:       attributes(keys, row),
+				Provenance: entity.Provenance{
+					File:  tag,
+					Table: 0,
+					Row:   i + 2,
+					Cells: cells(row),
+				},
 			},
 		}
 
@@ -78,14 +140,3 @@ func ParseTable(tag string, table *docx.Table) (out []entity.Record) {
 
 	return
 }
-
-func FindFirstTable(doc *docx.Docx) *docx.Table {
-	for _, it := range doc.Document.Body.Items {
-		switch it := it.(type) {
-		case *docx.Table:
-			return it
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadBasemap reads the GeoJSON drawn under the map of the report page: the border as lines or polygons
+// and the posts as points named by a "name" property; an empty path means no basemap
+func LoadBasemap(path string) (json.RawMessage, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBasemap(data)
+}
+
+// ParseBasemap checks that the data is GeoJSON and compacts it, since it is embedded into every report
+func ParseBasemap(data []byte) (json.RawMessage, error) {
+	object := struct {
+		Type string `json:"type"`
+	}{}
+
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	switch object.Type {
+	case "FeatureCollection", "Feature", "GeometryCollection",
+		"Point", "MultiPoint", "LineString", "MultiLineString", "Polygon", "MultiPolygon":
+	default:
+		return nil, fmt.Errorf("unknown GeoJSON type %q", object.Type)
+	}
+
+	out := bytes.Buffer{}
+
+	err = json.Compact(&out, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
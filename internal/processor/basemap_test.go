@@ -0,0 +1,22 @@
+package processor
+
+import "testing"
+
+func TestParseBasemap(t *testing.T) {
+	basemap, err := ParseBasemap([]byte(`{
+		"type": "FeatureCollection",
+		"features": [{"type": "Feature", "properties": {"name": "Кодима"}, "geometry": {"type": "Point", "coordinates": [29.15, 47.9]}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(basemap) != `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"name":"Кодима"},"geometry":{"type":"Point","coordinates":[29.15,47.9]}}]}` {
+		t.Errorf("unexpected basemap %s", basemap)
+	}
+
+	_, err = ParseBasemap([]byte(`{"features": []}`))
+	if err == nil {
+		t.Error("a basemap without a GeoJSON type was accepted")
+	}
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// expand replaces archives with their entries, recursively, and drops everything that is not a report;
+// files that claim to be reports but are not come back as failed files
+func expand(sources []Source, limits Limits) (out []Source, skipped []File, release func(), err error) {
+	releases := []func(){}
+
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+
+	var walk func(sources []Source) error
+
+	walk = func(sources []Source) error {
+		for _, source := range sources {
+			if !Supported(source.Name) {
+				continue
+			}
+
+			head, err := readHead(source)
+			if IsLimitError(err) {
+				return err
+			}
+			if err != nil {
+				skipped = append(skipped, File{Name: source.Name, Folder: source.Folder, Err: err})
+				continue
+			}
+
+			f, ok := detect(source.Name, head)
+			if !ok {
+				skipped = append(skipped, File{Name: source.Name, Folder: source.Folder, Err: fmt.Errorf("the content does not match the %s extension", path.Ext(source.Name))})
+				continue
+			}
+
+			if f != archiveFormat {
+				out = append(out, source)
+				continue
+			}
+
+			data, size, releaseArchive, err := open(source)
+			if IsLimitError(err) {
+				return err
+			}
+			if err != nil {
+				skipped = append(skipped, File{Name: source.Name, Folder: source.Folder, Err: err})
+				continue
+			}
+
+			releases = append(releases, releaseArchive)
+
+			total := source.total
+			if total == nil {
+				total = &atomic.Int64{}
+			}
+
+			// the entries of unit.zip end up in the unit folder
+			prefix := strings.TrimSuffix(source.Name, path.Ext(source.Name))
+
+			nested, err := zipSources(data, size, limits, prefix, source.Depth+1, total)
+			if IsLimitError(err) {
+				return err
+			}
+			if err != nil {
+				skipped = append(skipped, File{Name: source.Name, Folder: source.Folder, Err: err})
+				continue
+			}
+
+			// the entries are counted against MaxTotalSize as they are read, the archive holding them is not
+			if source.total != nil {
+				source.total.Add(-size)
+			}
+
+			err = walk(nested)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err = walk(sources)
+	if err != nil {
+		release()
+		return nil, nil, func() {}, err
+	}
+
+	return out, skipped, release, nil
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"go-doc-parser/internal/cfb"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	csv := []byte("№,Кінець\n1,19:10\n")
+	docx := buildZip(zipEntry{name: "word/document.xml", data: []byte("<w:document/>")})
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		sources []string // name and folder of every report
+		skipped []string
+	}{
+		{
+			name:    "reports",
+			entries: []zipEntry{{name: "a.csv", data: csv}, {name: "b.docx", data: docx}},
+			sources: []string{"a.csv ", "b.docx "},
+		},
+		{
+			name: "junk",
+			entries: []zipEntry{
+				{name: "unit/", data: nil},
+				{name: "__MACOSX/unit/._a.csv", data: csv},
+				{name: "unit/~$report.docx", data: docx},
+				{name: "unit/.hidden.csv", data: csv},
+				{name: "unit/notes.txt", data: csv},
+				{name: "unit/a.csv", data: csv},
+			},
+			sources: []string{"unit/a.csv unit"},
+		},
+		{
+			name: "nested",
+			entries: []zipEntry{
+				{name: "unit.zip", data: buildZip(zipEntry{name: "a.csv", data: csv}, zipEntry{name: "day/b.csv", data: csv})},
+				{name: "east/all.zip", data: buildZip(zipEntry{name: "inner.zip", data: buildZip(zipEntry{name: "c.csv", data: csv})}), store: true},
+			},
+			sources: []string{"unit/a.csv unit", "unit/day/b.csv unit/day", "east/all/inner/c.csv east/all/inner"},
+		},
+		{
+			name: "magic",
+			entries: []zipEntry{
+				{name: "fake.docx", data: []byte("not a zip")},
+				{name: "fake.doc", data: []byte("not a compound file")},
+				{name: "fake.zip", data: []byte("not a zip")},
+				{name: "protected.docx", data: append(bytes.Clone(cfb.Signature), make([]byte, 8)...)},
+				{name: "report.rtf", data: []byte("{\\rtf1 }")},
+			},
+			sources: []string{"protected.docx ", "report.rtf "},
+			skipped: []string{"fake.docx", "fake.doc", "fake.zip"},
+		},
+	}
+
+	for _, test := range tests {
+		upload := buildZip(test.entries...)
+
+		sources, err := ZipSources(bytes.NewReader(upload), int64(len(upload)), DefaultLimits)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		expanded, skipped, release, err := expand(sources, DefaultLimits)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		names := []string{}
+		for _, source := range expanded {
+			names = append(names, source.Name+" "+source.Folder)
+		}
+
+		skippedNames := []string{}
+		for _, file := range skipped {
+			if file.Err == nil {
+				t.Errorf("%s: %s was skipped without an error", test.name, file.Name)
+			}
+
+			skippedNames = append(skippedNames, file.Name)
+		}
+
+		if fmt.Sprint(names) != fmt.Sprint(test.sources) {
+			t.Errorf("%s: expected the reports %q, got %q", test.name, test.sources, names)
+		}
+
+		if fmt.Sprint(skippedNames) != fmt.Sprint(test.skipped) && len(skippedNames)+len(test.skipped) > 0 {
+			t.Errorf("%s: expected to skip %q, got %q", test.name, test.skipped, skippedNames)
+		}
+
+		release()
+	}
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"go-doc-parser/internal/document"
+	. "go-doc-parser/internal/entity"
+	"go-doc-parser/internal/parser"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// nearestCandidates is how many dictionary entries an explanation suggests
+const nearestCandidates = 3
+
+// NewExplainer tells for every record which dictionary supergroup it was counted in, if any,
+// and which dictionary entries its name is closest to
+func NewExplainer(dictionary [][]ID) func(records []Record) []Explanation {
+	supergroups := map[ShortID]string{}
+	entries := []ID{}
+
+	for _, group := range dictionary {
+		for _, id := range group {
+			if _, ok := supergroups[id.ShortID]; !ok {
+				supergroups[id.ShortID] = group[0].Name
+			}
+
+			entries = append(entries, id)
+		}
+	}
+
+	return func(records []Record) (out []Explanation) {
+		for _, record := range records {
+			source := record.Provenance
+
+			cell := ""
+			if parser.NameColumn < len(source.Cells) {
+				cell = source.Cells[parser.NameColumn]
+			}
+
+			// the tokens are not kept with the record, the cell is parsed again to find them
+			_, tokens := parser.ParseID(document.Cell{Paragraphs: strings.Split(cell, "\n")})
+
+			candidates := []Candidate{}
+			for _, entry := range entries {
+				candidates = append(candidates, Candidate{ID: entry, Distance: distance(fullName(record.ShortID), fullName(entry.ShortID))})
+			}
+
+			slices.SortStableFunc(candidates, func(a, b Candidate) int {
+				return a.Distance - b.Distance
+			})
+
+			out = append(out, Explanation{
+				File:       source.File,
+				Row:        source.Row,
+				Cell:       cell,
+				Tokens:     tokens,
+				ID:         record.ID,
+				Supergroup: supergroups[record.ShortID],
+				Nearest:    candidates[:min(len(candidates), nearestCandidates)],
+			})
+		}
+
+		return
+	}
+}
+
+func fullName(id ShortID) []rune {
+	return []rune(strings.ToLower(strings.TrimSpace(id.Type + " " + id.Name)))
+}
+
+// distance counts the characters to insert, delete or replace to turn a into b
+func distance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := range a {
+		current[0] = i + 1
+
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] || unicode.Is(unicode.Quotation_Mark, a[i]) && unicode.Is(unicode.Quotation_Mark, b[j]) {
+				cost = 0
+			}
+
+			current[j+1] = min(previous[j+1]+1, current[j]+1, previous[j]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"testing"
+)
+
+func TestExplainer(t *testing.T) {
+	kodyma := ID{ShortID: ShortID{Type: "впс", Name: "Кодима"}}
+	okny := ID{ShortID: ShortID{Type: "впс", Name: "Окни"}}
+
+	explain := NewExplainer([][]ID{{kodyma, okny}})
+
+	cells := func(name string) []string {
+		return []string{"", "", "", "", "", "", name, "", ""}
+	}
+
+	explanations := explain([]Record{
+		{ID: kodyma, Event: Event{Provenance: Provenance{File: "a.docx", Row: 2, Cells: cells("впс Кодима")}}},
+		{ID: ID{ShortID: ShortID{Type: "віпс", Name: "«Кодима»"}}, Event: Event{Provenance: Provenance{File: "a.docx", Row: 3, Cells: cells("віпс «Кодима»")}}},
+	})
+
+	if len(explanations) != 2 {
+		t.Fatalf("expected 2 explanations, got %d", len(explanations))
+	}
+
+	matched := explanations[0]
+	if matched.Supergroup != "Кодима" || len(matched.Tokens) != 1 || matched.Tokens[0] != "впс" || matched.Nearest[0].Distance != 0 {
+		t.Errorf("unexpected explanation %+v", matched)
+	}
+
+	other := explanations[1]
+	if other.Supergroup != "" || other.Row != 3 || other.Cell != "віпс «Кодима»" || other.Nearest[0].ID != kodyma {
+		t.Errorf("unexpected explanation %+v", other)
+	}
+}
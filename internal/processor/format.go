@@ -0,0 +1,119 @@
+package processor
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+
+	"go-doc-parser/internal/cfb"
+)
+
+type format int
+
+const (
+	unknownFormat format = iota
+	archiveFormat
+	docxFormat
+	odtFormat
+	docFormat
+	xlsxFormat
+	csvFormat
+	tsvFormat
+	rtfFormat
+)
+
+// extensions maps the accepted file extensions to the format they claim
+var extensions = map[string]format{
+	".zip":  archiveFormat,
+	".docx": docxFormat,
+	".odt":  odtFormat,
+	".doc":  docFormat,
+	".xlsx": xlsxFormat,
+	".csv":  csvFormat,
+	".tsv":  tsvFormat,
+	".rtf":  rtfFormat,
+}
+
+// magic is the signature every file of the format starts with, plain text formats have none
+var magic = map[format][]byte{
+	archiveFormat: []byte("PK\x03\x04"),
+	docxFormat:    []byte("PK\x03\x04"),
+	odtFormat:     []byte("PK\x03\x04"),
+	docFormat:     cfb.Signature,
+	xlsxFormat:    []byte("PK\x03\x04"),
+	rtfFormat:     []byte("{\\rtf"),
+}
+
+// encryptable are the zip based formats Office wraps into a compound file when they are protected with a password
+var encryptable = map[format]bool{
+	docxFormat: true,
+	xlsxFormat: true,
+}
+
+// Supported reports whether the file name looks like something the processor reads
+func Supported(name string) bool {
+	return !junk(name) && extensions[strings.ToLower(path.Ext(name))] != unknownFormat
+}
+
+// IsDocument is Supported without the archives
+func IsDocument(name string) bool {
+	return Supported(name) && extensions[strings.ToLower(path.Ext(name))] != archiveFormat
+}
+
+// junk matches folders and the files archivers and editors leave behind:
+// macOS resource forks, ~$ lock files of open Word documents and hidden files
+func junk(name string) bool {
+	if strings.HasSuffix(name, "/") {
+		return true
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" || strings.HasPrefix(part, "~$") || strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detect checks that the content starts like the format its extension claims or like its encrypted form
+func detect(name string, head []byte) (format, bool) {
+	f := extensions[strings.ToLower(path.Ext(name))]
+
+	return f, bytes.HasPrefix(head, magic[f]) || encryptable[f] && bytes.HasPrefix(head, cfb.Signature)
+}
+
+// encrypted tells a password protected document from a plain one of the same format
+func encrypted(f format, data io.ReaderAt) bool {
+	head := make([]byte, len(cfb.Signature))
+
+	_, err := data.ReadAt(head, 0)
+
+	return err == nil && encryptable[f] && bytes.Equal(head, cfb.Signature)
+}
+
+// readHead reads the first bytes of a source for detect, they are not counted against MaxTotalSize
+// since the source is read again from the start
+func readHead(source Source) ([]byte, error) {
+	opened, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer opened.Close()
+
+	head := make([]byte, 8)
+
+	n, err := io.ReadFull(opened, head)
+
+	if source.total != nil {
+		source.total.Add(-int64(n))
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	return head[:n], err
+}
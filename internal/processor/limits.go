@@ -0,0 +1,194 @@
+package processor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Limits protect the service from hostile uploads such as zip bombs, zero disables a limit
+type Limits struct {
+	MaxBodySize         int64 // bytes of the uploaded archive
+	MaxEntries          int   // entries of a single archive, the parts of a docx included
+	MaxTotalSize        int64 // uncompressed bytes read from all entries of an upload
+	MaxEntrySize        int64 // uncompressed bytes of a single entry
+	MaxCompressionRatio int64 // uncompressed to compressed size of a single entry
+	MaxDepth            int   // archives within archives, the upload is at depth 1 and the parts of a docx in it at depth 2
+	MaxXMLSize          int64 // bytes of the document XML
+	MaxXMLDepth         int   // element nesting of the document XML and group nesting of RTF
+}
+
+var DefaultLimits = Limits{
+	MaxBodySize:         64 << 20,
+	MaxEntries:          1000,
+	MaxTotalSize:        512 << 20,
+	MaxEntrySize:        64 << 20,
+	MaxCompressionRatio: 100,
+	MaxDepth:            4,
+	MaxXMLSize:          32 << 20,
+	MaxXMLDepth:         256,
+}
+
+// LimitError tells which limit an input breached
+type LimitError struct {
+	Limit    string // one of the Limits field names
+	Filename string
+}
+
+func (e *LimitError) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("the upload exceeds the %s limit", e.Limit)
+	}
+
+	return fmt.Sprintf("%s exceeds the %s limit", e.Filename, e.Limit)
+}
+
+// IsLimitError reports whether the error comes from a breached limit
+func IsLimitError(err error) bool {
+	var limitError *LimitError
+	return errors.As(err, &limitError)
+}
+
+// checkArchive validates what the headers of an archive at the given depth claim,
+// the actual sizes are enforced again while reading
+func (l Limits) checkArchive(name string, files []*zip.File, depth int) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &LimitError{Limit: "MaxDepth", Filename: name}
+	}
+
+	if l.MaxEntries > 0 && len(files) > l.MaxEntries {
+		return &LimitError{Limit: "MaxEntries", Filename: name}
+	}
+
+	total := uint64(0)
+
+	for _, file := range files {
+		total += file.UncompressedSize64
+
+		if l.MaxEntrySize > 0 && file.UncompressedSize64 > uint64(l.MaxEntrySize) {
+			return &LimitError{Limit: "MaxEntrySize", Filename: file.Name}
+		}
+
+		if l.MaxCompressionRatio > 0 && file.UncompressedSize64 > uint64(l.MaxCompressionRatio)*max(file.CompressedSize64, 1) {
+			return &LimitError{Limit: "MaxCompressionRatio", Filename: file.Name}
+		}
+	}
+
+	if l.MaxTotalSize > 0 && total > uint64(l.MaxTotalSize) {
+		return &LimitError{Limit: "MaxTotalSize", Filename: name}
+	}
+
+	return nil
+}
+
+// openEntry decompresses an entry without trusting its headers,
+// total is shared by all entries of an upload
+func (l Limits) openEntry(file *zip.File, total *atomic.Int64) (io.ReadCloser, error) {
+	opened, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedReader{
+		ReadCloser: opened,
+		limits:     l,
+		name:       file.Name,
+		compressed: int64(file.CompressedSize64),
+		total:      total,
+	}, nil
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	limits     Limits
+	name       string
+	compressed int64
+	read       int64
+	total      *atomic.Int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	r.read += int64(n)
+
+	if r.limits.MaxEntrySize > 0 && r.read > r.limits.MaxEntrySize {
+		return n, &LimitError{Limit: "MaxEntrySize", Filename: r.name}
+	}
+
+	if r.limits.MaxCompressionRatio > 0 && r.read > r.limits.MaxCompressionRatio*max(r.compressed, 1) {
+		return n, &LimitError{Limit: "MaxCompressionRatio", Filename: r.name}
+	}
+
+	if r.total != nil && r.limits.MaxTotalSize > 0 && r.total.Add(int64(n)) > r.limits.MaxTotalSize {
+		return n, &LimitError{Limit: "MaxTotalSize", Filename: r.name}
+	}
+
+	return n, err
+}
+
+// checkXML walks the document XML before it is unmarshalled, so deep or huge documents are refused early
+func (l Limits) checkXML(name string, file *zip.File) error {
+	if l.MaxXMLSize > 0 && file.UncompressedSize64 > uint64(l.MaxXMLSize) {
+		return &LimitError{Limit: "MaxXMLSize", Filename: name}
+	}
+
+	opened, err := file.Open()
+	if err != nil {
+		return err
+	}
+
+	defer opened.Close()
+
+	var reader io.Reader = opened
+	if l.MaxXMLSize > 0 {
+		reader = io.LimitReader(opened, l.MaxXMLSize+1)
+	}
+
+	counter := &countingReader{Reader: reader}
+
+	decoder := xml.NewDecoder(counter)
+
+	depth := 0
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+			if l.MaxXMLDepth > 0 && depth > l.MaxXMLDepth {
+				return &LimitError{Limit: "MaxXMLDepth", Filename: name}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if l.MaxXMLSize > 0 && counter.n > l.MaxXMLSize {
+		return &LimitError{Limit: "MaxXMLSize", Filename: name}
+	}
+
+	return nil
+}
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+
+	return n, err
+}
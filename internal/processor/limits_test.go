@@ -0,0 +1,221 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name  string
+	data  []byte
+	store bool // written without compression, so it is read in place
+}
+
+func buildZip(entries ...zipEntry) []byte {
+	buffer := bytes.Buffer{}
+
+	archive := zip.NewWriter(&buffer)
+	for _, entry := range entries {
+		method := zip.Deflate
+		if entry.store {
+			method = zip.Store
+		}
+
+		w, _ := archive.CreateHeader(&zip.FileHeader{Name: entry.name, Method: method})
+		w.Write(entry.data)
+	}
+	archive.Close()
+
+	return buffer.Bytes()
+}
+
+func limitName(err error) string {
+	var limit *LimitError
+	if errors.As(err, &limit) {
+		return limit.Limit
+	}
+
+	return ""
+}
+
+// readSources expands an upload and reads every report in full, like the processor does
+func readSources(upload []byte, limits Limits) error {
+	sources, err := ZipSources(bytes.NewReader(upload), int64(len(upload)), limits)
+	if err != nil {
+		return err
+	}
+
+	sources, _, release, err := expand(sources, limits)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	for _, source := range sources {
+		_, _, closeSource, err := open(source)
+		if err != nil {
+			return err
+		}
+
+		closeSource()
+	}
+
+	return nil
+}
+
+func TestZipBomb(t *testing.T) {
+	upload := buildZip(zipEntry{name: "bomb.csv", data: make([]byte, 10<<20)})
+
+	_, err := ZipSources(bytes.NewReader(upload), int64(len(upload)), DefaultLimits)
+	if limitName(err) != "MaxCompressionRatio" {
+		t.Errorf("expected the MaxCompressionRatio limit, got %v", err)
+	}
+}
+
+func TestLyingHeader(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1<<20)
+
+	// the deflated body of a real entry is copied raw under a header that claims it is as small as its compressed form
+	deflated := buildZip(zipEntry{name: "x", data: data})
+	reader, _ := zip.NewReader(bytes.NewReader(deflated), int64(len(deflated)))
+	raw, _ := reader.File[0].OpenRaw()
+	body, _ := io.ReadAll(raw)
+
+	buffer := bytes.Buffer{}
+	archive := zip.NewWriter(&buffer)
+	entry, _ := archive.CreateRaw(&zip.FileHeader{
+		Name:               "report.csv",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(body)),
+		UncompressedSize64: uint64(len(body)),
+	})
+	entry.Write(body)
+	archive.Close()
+
+	upload := buffer.Bytes()
+
+	sources, err := ZipSources(bytes.NewReader(upload), int64(len(upload)), DefaultLimits)
+	if err != nil {
+		t.Fatalf("the header was expected to pass, got %v", err)
+	}
+
+	opened, err := sources[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := io.Copy(io.Discard, opened)
+	if err == nil || n >= int64(len(data)) {
+		t.Errorf("read %d bytes of an entry larger than its header without an error", n)
+	}
+	if !errors.Is(err, zip.ErrFormat) && limitName(err) == "" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	entries := []zipEntry{}
+	for i := range 5 {
+		entries = append(entries, zipEntry{name: fmt.Sprintf("%d.csv", i), data: []byte("a")})
+	}
+
+	upload := buildZip(entries...)
+
+	limits := DefaultLimits
+	limits.MaxEntries = 3
+
+	_, err := ZipSources(bytes.NewReader(upload), int64(len(upload)), limits)
+	if limitName(err) != "MaxEntries" {
+		t.Errorf("expected the MaxEntries limit, got %v", err)
+	}
+
+	limits.MaxEntries = 0
+
+	_, err = ZipSources(bytes.NewReader(upload), int64(len(upload)), limits)
+	if err != nil {
+		t.Errorf("zero was expected to disable the limit, got %v", err)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	report := zipEntry{name: "report.csv", data: []byte("№,Кінець\n1,19:10\n")}
+
+	inner := buildZip(report)
+	middle := buildZip(zipEntry{name: "inner.zip", data: inner})
+	upload := buildZip(zipEntry{name: "middle.zip", data: middle})
+
+	limits := DefaultLimits
+	limits.MaxDepth = 2
+
+	err := readSources(upload, limits)
+	if limitName(err) != "MaxDepth" {
+		t.Errorf("expected the MaxDepth limit, got %v", err)
+	}
+
+	limits.MaxDepth = 3
+
+	err = readSources(upload, limits)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMaxTotalSizeCountsNestedArchivesOnce(t *testing.T) {
+	report := zipEntry{name: "report.csv", data: bytes.Repeat([]byte("1,19:10\n"), 125), store: true}
+
+	inner := buildZip(report)
+	upload := buildZip(zipEntry{name: "unit.zip", data: inner, store: true})
+
+	// the archive and the report each fit, counting the report twice would not
+	limits := DefaultLimits
+	limits.MaxTotalSize = int64(len(inner)) + 50
+
+	err := readSources(upload, limits)
+	if err != nil {
+		t.Errorf("the report fits the limit, got %v", err)
+	}
+
+	limits.MaxTotalSize = int64(len(report.data)) - 1
+
+	err = readSources(upload, limits)
+	if limitName(err) != "MaxTotalSize" {
+		t.Errorf("expected the MaxTotalSize limit, got %v", err)
+	}
+}
+
+func TestMaxXMLDepth(t *testing.T) {
+	document := strings.Repeat("<w:p>", 300) + strings.Repeat("</w:p>", 300)
+	upload := buildZip(zipEntry{name: "word/document.xml", data: []byte(document)})
+
+	reader, _ := zip.NewReader(bytes.NewReader(upload), int64(len(upload)))
+
+	err := DefaultLimits.checkXML("report.docx", reader.File[0])
+	if limitName(err) != "MaxXMLDepth" {
+		t.Errorf("expected the MaxXMLDepth limit, got %v", err)
+	}
+
+	limits := DefaultLimits
+	limits.MaxXMLDepth = 400
+
+	err = limits.checkXML("report.docx", reader.File[0])
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	// RTF groups nest like XML elements
+	rtf := `{\rtf1` + strings.Repeat("{", 300) + strings.Repeat("}", 300) + "}"
+
+	_, err = readTable(Source{Name: "report.rtf", Open: func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(rtf)), nil
+	}}, Options{Limits: DefaultLimits})
+	if limitName(err) != "MaxXMLDepth" {
+		t.Errorf("expected the MaxXMLDepth limit for RTF, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package processor
+
+import "context"
+
+type passwordsKey struct{}
+
+// WithPasswords hands the passwords of a batch to the processor, they are tried on encrypted documents before the keyring
+func WithPasswords(ctx context.Context, passwords []string) context.Context {
+	return context.WithValue(ctx, passwordsKey{}, passwords)
+}
+
+func passwordsFrom(ctx context.Context) []string {
+	passwords, _ := ctx.Value(passwordsKey{}).([]string)
+
+	return passwords
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"fmt"
+	. "go-doc-parser/internal/entity"
+	"slices"
+	"strings"
+)
+
+// PivotFields are the fields every event can be grouped by, the captured columns can be used as well
+// an event with several tags is counted once for each of them
+var PivotFields = []string{"type", "name", "hint", "supergroup", "hour", "shift", "file", "folder", "tag"}
+
+func shift(hour uint64) string {
+	if hour >= nightStart || hour < nightEnd {
+		return "ніч"
+	}
+
+	return "день"
+}
+
+// pivotEvent is an event together with where it was found
+type pivotEvent struct {
+	page       *Page
+	id         ID
+	supergroup string
+	event      Event
+}
+
+// values lists the values of a field, only the tags can have several or none
+func (e pivotEvent) values(name string) []string {
+	if name != "tag" {
+		return []string{e.field(name)}
+	}
+
+	if len(e.event.Tags) == 0 {
+		return []string{""}
+	}
+
+	return e.event.Tags
+}
+
+func (e pivotEvent) field(name string) string {
+	switch name {
+	case "type":
+		return e.id.Type
+	case "name":
+		return e.id.Name
+	case "hint":
+		return e.id.Hint
+	case "supergroup":
+		return e.supergroup
+	case "hour":
+		return fmt.Sprintf("%02d", e.event.End)
+	case "shift":
+		return shift(e.event.End)
+	case "file":
+		return e.page.Filename
+	case "folder":
+		return e.page.Folder
+	}
+
+	return e.event.Attributes[name]
+}
+
+// NewPivot groups the events of every page by the values of the fields,
+// rows are sorted by their values; the fields are checked against PivotFields and the columns of the pages
+func NewPivot(data Data, by []string) (Pivot, error) {
+	events := []pivotEvent{}
+	columns := map[string]bool{}
+
+	for i := range data.Pages {
+		page := &data.Pages[i]
+
+		for _, column := range page.Columns {
+			columns[column] = true
+		}
+
+		for _, groups := range page.SelectedSupergroups {
+			for _, group := range groups {
+				for _, event := range group.Events {
+					events = append(events, pivotEvent{page, group.ID, groups[0].Name, event})
+				}
+			}
+		}
+
+		for _, group := range page.OtherGroups {
+			for _, event := range group.Events {
+				events = append(events, pivotEvent{page, group.ID, "", event})
+			}
+		}
+	}
+
+	for _, field := range by {
+		if !slices.Contains(PivotFields, field) && !columns[field] {
+			return Pivot{}, fmt.Errorf("unknown pivot field %q", field)
+		}
+	}
+
+	out := Pivot{By: by}
+	rows := map[string]int{}
+
+	for _, e := range events {
+		// every combination of the field values is a row the event counts in
+		combinations := [][]string{{}}
+
+		for _, field := range by {
+			next := [][]string{}
+
+			for _, combination := range combinations {
+				for _, value := range e.values(field) {
+					next = append(next, append(slices.Clone(combination), value))
+				}
+			}
+
+			combinations = next
+		}
+
+		for _, values := range combinations {
+			key := strings.Join(values, "\x00")
+
+			index, ok := rows[key]
+			if !ok {
+				index = len(out.Rows)
+				rows[key] = index
+				out.Rows = append(out.Rows, PivotRow{Values: values, First: e.event.End, Last: e.event.End})
+			}
+
+			row := &out.Rows[index]
+			row.Count++
+			row.Duration += e.event.Duration
+			row.First = min(row.First, e.event.End)
+			row.Last = max(row.Last, e.event.End)
+		}
+	}
+
+	slices.SortFunc(out.Rows, func(a, b PivotRow) int {
+		return slices.Compare(a.Values, b.Values)
+	})
+
+	return out, nil
+}
+
+// ParsePivotFields splits a comma separated list of fields such as "name,hour"
+func ParsePivotFields(value string) []string {
+	out := []string{}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			out = append(out, field)
+		}
+	}
+
+	return out
+}
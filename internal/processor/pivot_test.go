@@ -0,0 +1,54 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"slices"
+	"testing"
+)
+
+func TestNewPivot(t *testing.T) {
+	event := func(end, duration uint64, direction string) Event {
+		return Event{End: end, Duration: duration, Attributes: map[string]string{"напрямок": direction}}
+	}
+
+	kodyma := ID{ShortID: ShortID{Type: "впс", Name: "Кодима"}}
+
+	data := Data{Pages: []Page{
+		{
+			Filename: "a.docx",
+			Columns:  []string{"напрямок"},
+			SelectedSupergroups: [][]Group{{
+				{ID: kodyma, Events: []Event{event(19, 30, "північ"), event(20, 10, "північ"), event(9, 0, "південь")}},
+			}},
+			OtherGroups: []Group{
+				{ID: ID{ShortID: ShortID{Name: "ГОРВ"}}, Events: []Event{event(21, 5, "північ")}},
+			},
+		},
+	}}
+
+	pivot, err := NewPivot(data, []string{"напрямок", "shift"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PivotRow{
+		{Values: []string{"південь", "день"}, Count: 1, First: 9, Last: 9},
+		{Values: []string{"північ", "ніч"}, Count: 3, Duration: 45, First: 19, Last: 21},
+	}
+
+	if !slices.EqualFunc(pivot.Rows, expected, func(a, b PivotRow) bool {
+		return slices.Equal(a.Values, b.Values) && a.Count == b.Count && a.Duration == b.Duration && a.First == b.First && a.Last == b.Last
+	}) {
+		t.Errorf("expected %v, got %v", expected, pivot.Rows)
+	}
+
+	pivot, err = NewPivot(data, []string{"supergroup"})
+	if err != nil || len(pivot.Rows) != 2 || pivot.Rows[1].Values[0] != "Кодима" || pivot.Rows[1].Count != 3 {
+		t.Errorf("unexpected supergroups %v, %v", pivot.Rows, err)
+	}
+
+	_, err = NewPivot(data, []string{"висота"})
+	if err == nil {
+		t.Error("an unknown field must be rejected")
+	}
+}
@@ -3,34 +3,183 @@ package processor
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-doc-parser/internal/document"
 	. "go-doc-parser/internal/entity"
+	"go-doc-parser/internal/extract"
+	"go-doc-parser/internal/offcrypto"
 	"go-doc-parser/internal/parser"
 	"io"
-
-	"github.com/fumiama/go-docx"
+	"path"
+	"slices"
+	"sort"
+	"sync"
 )
 
-func NewProcessor(dictionary [][]ID) func(files []*zip.File) (out Data) {
-	return func(files []*zip.File) (out Data) {
+// Options tune how a batch is processed
+type Options struct {
+	Workers    int // files parsed at the same time, at least one
+	Limits     Limits
+	Passwords  []string // the keyring tried on encrypted documents after the passwords of the batch
+	Revisions  document.Revisions
+	NoFindings parser.NoFindings  // comment paragraphs that are not cases, see parser.DefaultNoFindings
+	Rules      []Rule             // tag the events, see LoadRules
+	Summary    string             // the summary template, see DefaultSummaryTemplate
+	Gazetteer  *extract.Gazetteer // settlements recognized in the comments besides the ones after "с." or "смт"
+	Basemap    json.RawMessage    // drawn under the map of the report page, see LoadBasemap
+	Locations  map[ShortID]Point  // where the posts of the dictionary are, kept apart so the location is not part of the ID
+}
+
+func NewProcessor(dictionary [][]ID, options Options) func(ctx context.Context, sources []Source) (Data, error) {
+	aggregate := NewAggregator(dictionary, options)
 
-		aggregateComments := map[ID][]Event{}
+	workers := max(options.Workers, 1)
+
+	return func(ctx context.Context, sources []Source) (Data, error) {
+		sources, skipped, release, err := expand(sources, options.Limits)
+		if err != nil {
+			return Data{}, err
+		}
+
+		defer release()
+
+		// every worker writes to its own slot, so the pages keep the input order
+		files := make([]File, len(sources))
+
+		progress := progressFrom(ctx)
+
+		options := options
+		options.Passwords = slices.Concat(passwordsFrom(ctx), options.Passwords)
+
+		indexes := make(chan int)
+
+		mu := sync.Mutex{}
+		done := 0
+
+		wg := sync.WaitGroup{}
+
+		for range min(workers, len(sources)) {
+			wg.Add(1)
 
+			go func() {
+				defer wg.Done()
+
+				for i := range indexes {
+					files[i] = Parse(sources[i], options)
+
+					mu.Lock()
+					done++
+					progress(done, len(sources), files[i])
+					mu.Unlock()
+				}
+			}()
+		}
+
+	feed:
+		for i := range sources {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+
+		close(indexes)
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return Data{}, ctx.Err()
+		}
+
+		// a breached limit fails the whole batch instead of becoming a diagnostic
 		for _, file := range files {
-			opened, _ := file.Open()
+			if IsLimitError(file.Err) {
+				return Data{}, file.Err
+			}
+		}
 
-			reader, _ := io.ReadAll(opened)
+		return aggregate(append(skipped, files...)), nil
+	}
+}
 
-			doc, err := docx.Parse(bytes.NewReader(reader), int64(file.FileHeader.UncompressedSize64))
-			if err != nil {
-				panic(err)
-				// TODO: ???
+// File holds the records read from a single source, so they can be aggregated again without reparsing
+type File struct {
+	Name     string
+	Folder   string
+	Records  []Record
+	Columns  []string // attribute keys of the records in table order
+	Warnings []string // problems that did not stop the file from being read
+	Err      error
+}
+
+// Parse reads the records of a single source, errors are kept in the result
+func Parse(source Source, options Options) (file File) {
+	file.Name = source.Name
+	file.Folder = source.Folder
+
+	defer func() {
+		// malformed documents must not take the whole service down
+		if r := recover(); r != nil {
+			file.Records = nil
+			file.Columns = nil
+			file.Warnings = nil
+			file.Err = fmt.Errorf("failed to parse: %v", r)
+		}
+	}()
+
+	table, err := readTable(source, options)
+	if err != nil {
+		file.Err = err
+		return
+	}
+
+	// inject
+	file.Records = parser.ParseTable(source.Name, table, options.NoFindings)
+	file.Columns = parser.Columns(table)
+
+	Tag(file.Records, options.Rules)
+
+	for i := range file.Records {
+		record := &file.Records[i]
+
+		record.Mentions = extract.Mentions(record.Comment, options.Gazetteer)
+		record.Positions = append(extract.ParsePoints(record.Comment), extract.ParsePoints(record.Hint)...)
+	}
+
+	for i, row := range table.Rows {
+		if row.Revised {
+			file.Warnings = append(file.Warnings, fmt.Sprintf("row %d has unresolved tracked changes, read with the %s mode", i+1, options.Revisions))
+		}
+	}
+
+	return
+}
+
+// NewAggregator groups already parsed files into pages and aggregates following the dictionary,
+// the summary follows the template of the options and the basemap is passed on to the report page
+func NewAggregator(dictionary [][]ID, options Options) func(files []File) (out Data) {
+	explain := NewExplainer(dictionary)
+
+	return func(files []File) (out Data) {
+
+		aggregateComments := map[ID][]Event{}
+		aggregateSelected := make([][]Event, len(dictionary))
+		aggregateOther := map[ID][]Event{}
+
+		for _, file := range files {
+			if file.Err != nil {
+				out.Diagnostics = append(out.Diagnostics, Diagnostic{Filename: file.Name, Message: file.Err.Error()})
+				continue
 			}
 
-			// inject
-			table := parser.FindFirstTable(doc)
+			for _, warning := range file.Warnings {
+				out.Diagnostics = append(out.Diagnostics, Diagnostic{Filename: file.Name, Message: warning})
+			}
 
-			// inject
-			records := parser.ParseTable(file.Name, table)
+			records := file.Records
 
 			p := Collector{
 				EventsBySelectedIDs: map[ShortID][]Event{},
@@ -46,17 +195,20 @@ func NewProcessor(dictionary [][]ID) func(files []*zip.File) (out Data) {
 
 			p.Collect(records)
 
+			out.Explanations = append(out.Explanations, explain(records)...)
+
 			for id, comments := range p.CommentsByIDs {
 				aggregateComments[id] = append(aggregateComments[id], comments...)
 			}
 
 			selectedSupergroups := [][]Group{}
 
-			for _, groupIDs := range dictionary {
+			for i, groupIDs := range dictionary {
 				groups := []Group{}
 				for _, groupID := range groupIDs {
 					events := p.EventsBySelectedIDs[groupID.ShortID]
-					groups = append(groups, Group{ID: groupID, Events: events})
+					groups = append(groups, Group{ID: groupID, Location: options.Locations[groupID.ShortID], Events: events})
+					aggregateSelected[i] = append(aggregateSelected[i], events...)
 				}
 
 				selectedSupergroups = append(selectedSupergroups, groups)
@@ -65,11 +217,16 @@ func NewProcessor(dictionary [][]ID) func(files []*zip.File) (out Data) {
 			otherGroups := []Group{}
 
 			for id, group := range p.EventsByOtherIDs {
-				otherGroups = append(otherGroups, Group{id, group})
+				otherGroups = append(otherGroups, Group{ID: id, Location: options.Locations[id.ShortID], Events: group})
+				aggregateOther[id] = append(aggregateOther[id], group...)
 			}
 
+			sortGroups(otherGroups)
+
 			page := Page{
-				Filename:            file.FileHeader.Name,
+				Filename:            file.Name,
+				Folder:              file.Folder,
+				Columns:             file.Columns,
 				SelectedSupergroups: selectedSupergroups,
 				OtherGroups:         otherGroups,
 			}
@@ -77,18 +234,146 @@ func NewProcessor(dictionary [][]ID) func(files []*zip.File) (out Data) {
 			out.Pages = append(out.Pages, page)
 		}
 
-		summary := ""
+		for i, groupIDs := range dictionary {
+			if len(groupIDs) < 1 {
+				continue
+			}
+
+			// supergroups are named after their first entry
+			out.AggregatedSelected = append(out.AggregatedSelected, Group{ID: groupIDs[0], Location: options.Locations[groupIDs[0].ShortID], Events: aggregateSelected[i]})
+		}
+
+		for id, events := range aggregateOther {
+			out.AggregatedOther = append(out.AggregatedOther, Group{ID: id, Location: options.Locations[id.ShortID], Events: events})
+		}
+
+		sortGroups(out.AggregatedOther)
 
 		for id, comments := range aggregateComments {
-			out.AggregatedComments = append(out.AggregatedComments, Group{id, comments})
+			out.AggregatedComments = append(out.AggregatedComments, Group{ID: id, Events: comments})
 		}
 
-		out.Summary = summary
+		sortGroups(out.AggregatedComments)
+
+		out.SummaryTemplate = options.Summary
+		out.Basemap = options.Basemap
+		out.Summary = Summary(out)
 
 		return
 	}
 }
 
+// readTable finds the report table of a source in whatever format it comes
+func readTable(source Source, options Options) (document.Table, error) {
+	data, size, release, err := open(source)
+	if err != nil {
+		return document.Table{}, err
+	}
+
+	defer release()
+
+	format, _ := detect(source.Name, nil)
+
+	limits := options.Limits
+
+	if encrypted(format, data) {
+		plain, err := offcrypto.Decrypt(data, size, options.Passwords)
+		if err != nil {
+			return document.Table{}, err
+		}
+
+		// the plaintext stays in memory, it is never spooled
+		data, size = bytes.NewReader(plain), int64(len(plain))
+	}
+
+	var table document.Table
+
+	switch format {
+	case odtFormat:
+		err = checkPackage(source, data, size, limits, "content.xml")
+		if err != nil {
+			return document.Table{}, err
+		}
+
+		table, err = document.ReadODT(data, size, options.Revisions)
+	case docFormat:
+		table, err = document.ReadDoc(data, size)
+	case xlsxFormat:
+		err = checkPackage(source, data, size, limits, "xl/sharedStrings.xml", "xl/worksheets/*.xml")
+		if err != nil {
+			return document.Table{}, err
+		}
+
+		table, err = document.ReadXLSX(data, size)
+	case csvFormat:
+		table, err = document.ReadCSV(data, size, 0)
+	case tsvFormat:
+		table, err = document.ReadCSV(data, size, '\t')
+	case rtfFormat:
+		table, err = document.ReadRTF(data, size, limits.MaxXMLDepth)
+		if errors.Is(err, document.ErrTooDeep) {
+			err = &LimitError{Limit: "MaxXMLDepth", Filename: source.Name}
+		}
+	default:
+		err = checkPackage(source, data, size, limits, "word/document.xml")
+		if err != nil {
+			return document.Table{}, err
+		}
+
+		table, err = document.ReadDocx(data, size, options.Revisions)
+	}
+
+	return table, err
+}
+
+// checkPackage applies the limits to the parts of a zip based document before it is unpacked,
+// archive/zip itself refuses parts larger than their headers claim; parts are path.Match patterns of the XML to walk
+func checkPackage(source Source, data io.ReaderAt, size int64, limits Limits, parts ...string) error {
+	archive, err := zip.NewReader(data, size)
+	if err != nil {
+		return err
+	}
+
+	err = limits.checkArchive(source.Name, archive.File, source.Depth+1)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range archive.File {
+		for _, part := range parts {
+			if matched, _ := path.Match(part, file.Name); !matched {
+				continue
+			}
+
+			err = limits.checkXML(source.Name, file)
+			if err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// sortGroups keeps groups that come from map iteration in a stable order
+func sortGroups(groups []Group) {
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].ID, groups[j].ID
+
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.Hint < b.Hint
+	})
+}
+
 // collect all events selected and other plus comments and group them by id
 type Collector struct {
 	EventsBySelectedIDs map[ShortID][]Event // need to fill in empty items for all selected ids before using
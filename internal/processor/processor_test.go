@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	. "go-doc-parser/internal/entity"
+	"io"
+	"strings"
+	"testing"
+)
+
+func failingSources(n int) (out []Source) {
+	for i := range n {
+		out = append(out, Source{
+			Name: fmt.Sprintf("%02d.docx", i),
+			Open: func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("PK\x03\x04 but not a zip")), nil
+			},
+		})
+	}
+
+	return
+}
+
+func TestProcessorKeepsOrder(t *testing.T) {
+	process := NewProcessor(nil, Options{Workers: 4, Limits: DefaultLimits})
+
+	calls := 0
+	ctx := WithProgress(context.Background(), func(done, total int, file File) {
+		calls++
+	})
+
+	data, err := process(ctx, failingSources(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 20 {
+		t.Errorf("progress called %d times", calls)
+	}
+
+	for i, diagnostic := range data.Diagnostics {
+		if diagnostic.Filename != fmt.Sprintf("%02d.docx", i) {
+			t.Errorf("diagnostic %d is for %s", i, diagnostic.Filename)
+		}
+	}
+}
+
+func TestProcessorCancelled(t *testing.T) {
+	process := NewProcessor(nil, Options{Workers: 2, Limits: DefaultLimits})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := process(ctx, failingSources(20))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
+
+func TestAggregatorLocations(t *testing.T) {
+	kodyma := ID{ShortID: ShortID{Type: "впс", Name: "Кодима"}}
+	larga := ID{ShortID: ShortID{Type: "впс", Name: "Ларга"}}
+
+	aggregate := NewAggregator([][]ID{{kodyma}}, Options{Locations: map[ShortID]Point{
+		kodyma.ShortID: {Lat: 47.75, Lon: 29.5},
+		larga.ShortID:  {Lat: 48.35, Lon: 26.85},
+	}})
+
+	files := []File{
+		{Name: "1.csv", Records: []Record{{ID: kodyma, Event: Event{End: 19}}, {ID: larga, Event: Event{End: 20}}}},
+		{Name: "2.csv", Records: []Record{{ID: larga, Event: Event{End: 21}}}},
+	}
+
+	data := aggregate(files)
+
+	if len(data.AggregatedSelected) != 1 || data.AggregatedSelected[0].Location != (Point{Lat: 47.75, Lon: 29.5}) {
+		t.Errorf("expected the location of the selected post, got %+v", data.AggregatedSelected)
+	}
+
+	// the location is looked up by the post, it does not split its events
+	if len(data.AggregatedOther) != 1 || len(data.AggregatedOther[0].Events) != 2 || data.AggregatedOther[0].Location != (Point{Lat: 48.35, Lon: 26.85}) {
+		t.Errorf("expected one other post with both events and its location, got %+v", data.AggregatedOther)
+	}
+
+	if group := data.Pages[0].SelectedSupergroups[0][0]; group.Location != (Point{Lat: 47.75, Lon: 29.5}) {
+		t.Errorf("expected the location on the page, got %+v", group)
+	}
+}
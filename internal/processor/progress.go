@@ -0,0 +1,23 @@
+package processor
+
+import "context"
+
+// Progress is called after each source of a batch has been parsed,
+// the calls never overlap even though the sources are parsed concurrently
+type Progress func(done, total int, file File)
+
+type progressKey struct{}
+
+// WithProgress asks the processor to report every parsed source to the callback
+func WithProgress(ctx context.Context, progress Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+func progressFrom(ctx context.Context) Progress {
+	progress, ok := ctx.Value(progressKey{}).(Progress)
+	if !ok {
+		return func(done, total int, file File) {}
+	}
+
+	return progress
+}
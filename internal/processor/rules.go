@@ -0,0 +1,166 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	. "go-doc-parser/internal/entity"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Rule tags the events it matches; the text conditions look at the comment and at least one of them must match,
+// the field conditions must all match; the patterns are compiled on first use, ParseRules reports the invalid ones
+type Rule struct {
+	Tag      string
+	Keywords []string          // any of them found in the comment, the case ignored
+	Patterns []string          // any of them matching the comment, the case ignored
+	Fields   map[string]string // patterns for the fields type, name, hint and hour or for the captured columns
+}
+
+// patterns caches the compiled patterns of every rule, so rules written directly into Options match like parsed ones
+var patterns sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := patterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(pattern, compiled)
+
+	return compiled, nil
+}
+
+// matches tells whether the pattern matches the text, an invalid pattern matches nothing
+func matches(pattern, text string) bool {
+	compiled, err := compile(pattern)
+
+	return err == nil && compiled.MatchString(text)
+}
+
+// LoadRules reads the tagging rules from a JSON file holding a list of Rule, an empty path means none
+func LoadRules(path string) ([]Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRules(data)
+}
+
+// ParseRules reads and checks the tagging rules
+func ParseRules(data []byte) ([]Rule, error) {
+	rules := []Rule{}
+
+	err := json.Unmarshal(data, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range rules {
+		if rule.Tag == "" {
+			return nil, fmt.Errorf("rule %d has no tag", i+1)
+		}
+
+		if len(rule.Keywords) == 0 && len(rule.Patterns) == 0 && len(rule.Fields) == 0 {
+			return nil, fmt.Errorf("rule %q has no conditions", rule.Tag)
+		}
+
+		for _, pattern := range rule.Patterns {
+			_, err := compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.Tag, err)
+			}
+		}
+
+		for field, pattern := range rule.Fields {
+			_, err := compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q, field %s: %w", rule.Tag, field, err)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+func recordField(record Record, name string) string {
+	switch name {
+	case "type":
+		return record.Type
+	case "name":
+		return record.Name
+	case "hint":
+		return record.Hint
+	case "hour":
+		return fmt.Sprintf("%02d", record.End)
+	}
+
+	return record.Attributes[name]
+}
+
+// Match tells whether the rule applies to the record
+func (r Rule) Match(record Record) bool {
+	if len(r.Keywords) > 0 || len(r.Patterns) > 0 {
+		comment := strings.ToLower(record.Comment)
+
+		found := slices.ContainsFunc(r.Keywords, func(keyword string) bool {
+			return strings.Contains(comment, strings.ToLower(keyword))
+		}) || slices.ContainsFunc(r.Patterns, func(pattern string) bool {
+			return matches(pattern, record.Comment)
+		})
+
+		if !found {
+			return false
+		}
+	}
+
+	for field, pattern := range r.Fields {
+		if !matches(pattern, recordField(record, field)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Tag sets the tags of every record, in the order of the rules
+func Tag(records []Record, rules []Rule) {
+	for i := range records {
+		tags := []string{}
+
+		for _, rule := range rules {
+			if !slices.Contains(tags, rule.Tag) && rule.Match(records[i]) {
+				tags = append(tags, rule.Tag)
+			}
+		}
+
+		if len(tags) > 0 {
+			records[i].Tags = tags
+		}
+	}
+}
+
+// FilterTags keeps the events carrying any of the tags, no tags keeps everything
+func FilterTags(data Data, tags []string) Data {
+	if len(tags) == 0 {
+		return data
+	}
+
+	return filterEvents(data, func(event Event) bool {
+		return slices.ContainsFunc(event.Tags, func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	})
+}
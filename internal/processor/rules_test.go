@@ -0,0 +1,75 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	rules, err := ParseRules([]byte(`[
+		{"Tag": "затримання", "Keywords": ["ЗАТРИМ"]},
+		{"Tag": "постріли", "Patterns": ["пострі\\w*"], "Fields": {"name": "^Кодима$"}},
+		{"Tag": "вночі", "Fields": {"hour": "^(1[89]|2\\d)$", "тип": "БпЛА"}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kodyma := ID{ShortID: ShortID{Type: "впс", Name: "Кодима"}}
+	okny := ID{ShortID: ShortID{Type: "впс", Name: "Окни"}}
+
+	records := []Record{
+		{ID: kodyma, Event: Event{End: 19, Comment: "Постріли, затримано двох", Attributes: map[string]string{"тип": "БпЛА"}}},
+		{ID: okny, Event: Event{End: 9, Comment: "Постріли"}},
+		{ID: okny, Event: Event{End: 20, Attributes: map[string]string{"тип": "гелікоптер"}}},
+	}
+
+	Tag(records, rules)
+
+	expected := [][]string{{"затримання", "постріли", "вночі"}, nil, nil}
+	for i, record := range records {
+		if !slices.Equal(record.Tags, expected[i]) {
+			t.Errorf("record %d: expected %q, got %q", i, expected[i], record.Tags)
+		}
+	}
+
+	// rules written directly match like the parsed ones
+	direct := Rule{Tag: "постріли", Patterns: []string{"пострі\\w*"}, Fields: map[string]string{"name": "^Окни$"}}
+	if !direct.Match(records[1]) || direct.Match(records[0]) {
+		t.Error("expected the rule to match the second record only")
+	}
+
+	for _, invalid := range []string{`[{"Tag": "x"}]`, `[{"Keywords": ["x"]}]`, `[{"Tag": "x", "Patterns": ["("]}]`} {
+		if _, err := ParseRules([]byte(invalid)); err == nil {
+			t.Errorf("%s should be rejected", invalid)
+		}
+	}
+}
+
+func TestSummaryTemplate(t *testing.T) {
+	tagged := Event{Tags: []string{"постріли", "затримання"}}
+
+	data := Data{
+		SummaryTemplate: "{groups}Постріли: {tag:постріли}, невідомо: {tag:інше}\n{tags}{flights} {unknown}",
+		AggregatedSelected: []Group{
+			{ID: ID{ShortID: ShortID{Name: "Кодима"}}, Events: []Event{tagged, {}}},
+		},
+		AggregatedOther: []Group{
+			{Events: []Event{{Tags: []string{"постріли"}}}},
+		},
+	}
+
+	expected := "1. Кодима - польотів: 2, ОПДК не виявлено;\n" +
+		"Постріли: 2, невідомо: 0\n" +
+		"затримання: 1;\nпостріли: 2;\n2 {unknown}"
+
+	if got := Summary(data); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	if got := Summary(FilterTags(data, []string{"затримання"})); !strings.HasPrefix(got, "1. Кодима - польотів: 1,") {
+		t.Errorf("the filter keeps untagged events: %s", got)
+	}
+}
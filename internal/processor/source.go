@@ -0,0 +1,178 @@
+package processor
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// Source is a single report to process, either a zip entry or a file on disk
+type Source struct {
+	Name   string // path within the upload, folders included
+	Folder string // folder of the report within the upload, empty at the top level
+	Depth  int    // nesting of the archive the report comes from, zero for files on disk
+	Open   func() (io.ReadCloser, error)
+
+	// Random gives direct random access to sources that are not compressed, it may be nil
+	Random func() (data io.ReaderAt, size int64, release func(), err error)
+
+	total *atomic.Int64 // uncompressed bytes read from the upload so far
+}
+
+// ZipSources wraps the entries of an archive once its headers pass the limits,
+// the entries are checked again while they are decompressed
+func ZipSources(archive io.ReaderAt, size int64, limits Limits) ([]Source, error) {
+	return zipSources(archive, size, limits, "", 1, &atomic.Int64{})
+}
+
+// zipSources names the entries after the archive they are nested in, so prefix is a folder
+func zipSources(archive io.ReaderAt, size int64, limits Limits, prefix string, depth int, total *atomic.Int64) ([]Source, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, err
+	}
+
+	err = limits.checkArchive(prefix, reader.File, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Source{}
+
+	for _, file := range reader.File {
+		name := path.Join(prefix, file.Name)
+
+		source := Source{
+			Name:   name,
+			Folder: folder(name),
+			Depth:  depth,
+			Open: func() (io.ReadCloser, error) {
+				return limits.openEntry(file, total)
+			},
+			total: total,
+		}
+
+		if file.Method == zip.Store {
+			// stored entries are read in place, without a copy
+			source.Random = func() (io.ReaderAt, int64, func(), error) {
+				offset, err := file.DataOffset()
+				if err != nil {
+					return nil, 0, nil, err
+				}
+
+				size := int64(file.UncompressedSize64)
+
+				if limits.MaxTotalSize > 0 && total.Add(size) > limits.MaxTotalSize {
+					return nil, 0, nil, &LimitError{Limit: "MaxTotalSize", Filename: name}
+				}
+
+				return io.NewSectionReader(archive, offset, size), size, func() {}, nil
+			}
+		}
+
+		if file.FileInfo().IsDir() {
+			source.Name += "/"
+		}
+
+		out = append(out, source)
+	}
+
+	return out, nil
+}
+
+func folder(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+
+	return dir
+}
+
+// FileSource reads a report straight from disk
+func FileSource(path string) Source {
+	return Source{
+		Name: filepath.Base(path),
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+		Random: func() (io.ReaderAt, int64, func(), error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, 0, nil, err
+			}
+
+			return file, info.Size(), func() { file.Close() }, nil
+		},
+	}
+}
+
+// PathSources expands the command line arguments into reports and archives,
+// directories contribute every file found inside them named after their folders
+func PathSources(paths []string) (out []Source, err error) {
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return out, err
+		}
+
+		if !info.IsDir() {
+			out = append(out, FileSource(root))
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			source := FileSource(path)
+			source.Name = filepath.ToSlash(rel)
+			source.Folder = folder(source.Name)
+
+			out = append(out, source)
+
+			return nil
+		})
+		if err != nil {
+			return out, err
+		}
+	}
+
+	return
+}
+
+// open gives random access to a source: files on disk and stored entries are read in place,
+// compressed entries are decompressed into memory when small and into a temporary file otherwise
+func open(source Source) (io.ReaderAt, int64, func(), error) {
+	if source.Random != nil {
+		return source.Random()
+	}
+
+	opened, err := source.Open()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	defer opened.Close()
+
+	return Spool(opened)
+}
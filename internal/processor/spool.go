@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// SpoolThreshold is the size up to which data is kept in memory, larger data goes to a temporary file
+const SpoolThreshold = 4 << 20
+
+// Spool turns a stream into random access data for zip.NewReader and the document readers;
+// release removes the temporary file, if there is one
+func Spool(r io.Reader) (data io.ReaderAt, size int64, release func(), err error) {
+	buffer := bytes.Buffer{}
+
+	n, err := io.CopyN(&buffer, r, SpoolThreshold+1)
+	if err == io.EOF {
+		return bytes.NewReader(buffer.Bytes()), n, func() {}, nil
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	file, err := os.CreateTemp("", "go-doc-parser-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	release = func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	_, err = file.Write(buffer.Bytes())
+	if err != nil {
+		release()
+		return nil, 0, nil, err
+	}
+
+	copied, err := io.Copy(file, r)
+	if err != nil {
+		release()
+		return nil, 0, nil, err
+	}
+
+	return file, n + copied, release, nil
+}
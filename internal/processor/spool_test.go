@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	for _, size := range []int{0, SpoolThreshold, SpoolThreshold + 1, 2*SpoolThreshold + 7} {
+		input := make([]byte, size)
+		for i := range input {
+			input[i] = byte(i * 7)
+		}
+
+		data, n, release, err := Spool(bytes.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != int64(size) {
+			t.Errorf("%d: expected the size %d, got %d", size, size, n)
+		}
+
+		file, spooled := data.(*os.File)
+		if spooled != (size > SpoolThreshold) {
+			t.Errorf("%d: expected a temporary file only above the threshold, got %T", size, data)
+		}
+
+		got, err := io.ReadAll(io.NewSectionReader(data, 0, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, input) {
+			t.Errorf("%d: the spooled data differs from the input", size)
+		}
+
+		release()
+
+		if spooled {
+			if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+				t.Errorf("%d: expected release to remove %s, got %v", size, file.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"fmt"
+	. "go-doc-parser/internal/entity"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultSummaryTemplate lists the supergroups, the way the summary has always looked
+const DefaultSummaryTemplate = "{groups}"
+
+// the night shift runs from nightStart to nightEnd the next morning,
+// the "18:00-00:00" switch on the report page cuts off at its start; report.js keeps the same hours
+const (
+	nightStart = 18
+	nightEnd   = 6
+)
+
+// placeholder finds the placeholders of a summary template:
+// {groups} the line of every supergroup, {flights} the events of all supergroups,
+// {tag:NAME} the events carrying the tag and {tags} a line for every tag; others are left as they are
+var placeholder = regexp.MustCompile(`\{(groups|flights|tags|tag:[^{}]+)\}`)
+
+// Summary renders the same text the report page puts into its summary box, following the template of the data
+func Summary(data Data) string {
+	template := data.SummaryTemplate
+	if template == "" {
+		template = DefaultSummaryTemplate
+	}
+
+	tags := map[string]int{}
+	for _, groups := range [][]Group{data.AggregatedSelected, data.AggregatedOther} {
+		for _, group := range groups {
+			for _, event := range group.Events {
+				for _, tag := range event.Tags {
+					tags[tag]++
+				}
+			}
+		}
+	}
+
+	return placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+
+		switch name {
+		case "groups":
+			return groupsSummary(data)
+		case "flights":
+			flights := 0
+			for _, group := range data.AggregatedSelected {
+				flights += len(group.Events)
+			}
+
+			return strconv.Itoa(flights)
+		case "tags":
+			out := strings.Builder{}
+
+			for _, tag := range slices.Sorted(maps.Keys(tags)) {
+				fmt.Fprintf(&out, "%s: %d;\n", tag, tags[tag])
+			}
+
+			return out.String()
+		}
+
+		return strconv.Itoa(tags[strings.TrimPrefix(name, "tag:")])
+	})
+}
+
+// LoadSummaryTemplate reads a summary template from a file, an empty path gives the default template
+func LoadSummaryTemplate(path string) (string, error) {
+	if path == "" {
+		return DefaultSummaryTemplate, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	return string(data), err
+}
+
+// groupsSummary has a line for every supergroup with its flights and cases
+func groupsSummary(data Data) string {
+	out := strings.Builder{}
+
+	casesText := Plural("випадку", "випадках", "випадках")
+
+	for index, group := range data.AggregatedSelected {
+		subsummary := "ОПДК не виявлено"
+
+		count, detained, unknown := 0, 0, 0
+		for _, event := range group.Events {
+			if len(event.Comment) > 0 {
+				count++
+
+				if len(event.DetainedEvidence) > 0 {
+					detained += event.Detained
+				} else {
+					unknown++
+				}
+			}
+		}
+
+		if count > 0 {
+			subsummary = fmt.Sprintf("в %s %s затриманих", casesText(count), detainedText(detained, unknown))
+		}
+
+		fmt.Fprintf(&out, "%d. %s - польотів: %d, %s;\n", index+1, group.Name, len(group.Events), subsummary)
+	}
+
+	return out.String()
+}
+
+// detainedText fills the count of detained persons, the "_" placeholder stays for the comments
+// the count could not be read from, so the operator knows what is left to check
+func detainedText(detained, unknown int) string {
+	switch {
+	case unknown == 0:
+		return strconv.Itoa(detained)
+	case detained > 0:
+		return fmt.Sprintf("%d + _", detained)
+	}
+
+	return "_"
+}
+
+// Plural picks the Ukrainian word form that agrees with n
+func Plural(one, few, many string) func(n int) string {
+	return func(n int) string {
+		mod10 := n % 10
+		mod100 := n % 100
+
+		out := many
+
+		if mod10 == 1 && mod100 != 11 {
+			out = one
+		} else if mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14) {
+			out = few
+		}
+
+		return fmt.Sprintf("%d %s", n, out)
+	}
+}
+
+// Cutoff drops the events that ended before the given hour, like the "18:00-00:00" switch on the report page;
+// zero keeps everything
+func Cutoff(data Data, hour uint64) Data {
+	if hour == 0 {
+		return data
+	}
+
+	return filterEvents(data, func(event Event) bool {
+		return event.End >= hour
+	})
+}
+
+// filterEvents keeps the events of every group that pass, the summary is rendered again
+func filterEvents(data Data, keep func(Event) bool) Data {
+	filter := func(groups []Group) []Group {
+		out := []Group{}
+
+		for _, group := range groups {
+			events := []Event{}
+
+			for _, event := range group.Events {
+				if keep(event) {
+					events = append(events, event)
+				}
+			}
+
+			out = append(out, Group{ID: group.ID, Events: events})
+		}
+
+		return out
+	}
+
+	out := data
+	out.Pages = nil
+
+	for _, page := range data.Pages {
+		selected := [][]Group{}
+
+		for _, groups := range page.SelectedSupergroups {
+			selected = append(selected, filter(groups))
+		}
+
+		page.SelectedSupergroups = selected
+		page.OtherGroups = filter(page.OtherGroups)
+
+		out.Pages = append(out.Pages, page)
+	}
+
+	out.AggregatedSelected = filter(data.AggregatedSelected)
+	out.AggregatedOther = filter(data.AggregatedOther)
+	out.AggregatedComments = filter(data.AggregatedComments)
+	out.Summary = Summary(out)
+
+	return out
+}
@@ -0,0 +1,25 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"testing"
+)
+
+func TestSummaryDetained(t *testing.T) {
+	counted := Event{Comment: "Затримано двох громадян.", Detained: 2, DetainedEvidence: []string{"Затримано двох громадян."}}
+	unknown := Event{Comment: "Затримано."}
+
+	data := Data{AggregatedSelected: []Group{
+		{ID: ID{ShortID: ShortID{Name: "Кодима"}}, Events: []Event{counted, counted, {}}},
+		{ID: ID{ShortID: ShortID{Name: "Окни"}}, Events: []Event{counted, unknown}},
+		{ID: ID{ShortID: ShortID{Name: "Чорна"}}, Events: []Event{unknown}},
+	}}
+
+	expected := "1. Кодима - польотів: 3, в 2 випадках 4 затриманих;\n" +
+		"2. Окни - польотів: 2, в 2 випадках 2 + _ затриманих;\n" +
+		"3. Чорна - польотів: 1, в 1 випадку _ затриманих;\n"
+
+	if got := Summary(data); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
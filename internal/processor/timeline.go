@@ -0,0 +1,43 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"slices"
+	"strings"
+)
+
+// NewTimeline counts the aggregated events by the hour they ended; the known posts come first
+// in the dictionary order and keep their row without events, the other posts follow by name
+func NewTimeline(data Data) (timeline Timeline) {
+	count := func(name string, events []Event) TimelinePost {
+		post := TimelinePost{Name: name}
+
+		for _, event := range events {
+			post.Hours[event.End%24]++
+			timeline.Hours[event.End%24]++
+		}
+
+		return post
+	}
+
+	for _, group := range data.AggregatedSelected {
+		timeline.Posts = append(timeline.Posts, count(group.Name, group.Events))
+	}
+
+	other := []TimelinePost{}
+
+	for _, group := range data.AggregatedOther {
+		if len(group.Events) > 0 {
+			name := slices.DeleteFunc([]string{group.Type, group.Name, group.Hint}, func(part string) bool { return part == "" })
+			other = append(other, count(strings.Join(name, " "), group.Events))
+		}
+	}
+
+	slices.SortStableFunc(other, func(a, b TimelinePost) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	timeline.Posts = append(timeline.Posts, other...)
+
+	return
+}
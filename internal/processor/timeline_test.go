@@ -0,0 +1,39 @@
+package processor
+
+import (
+	. "go-doc-parser/internal/entity"
+	"testing"
+)
+
+func TestNewTimeline(t *testing.T) {
+	data := Data{
+		AggregatedSelected: []Group{
+			{ID: ID{ShortID: ShortID{Type: "впс", Name: "Кодима"}}, Events: []Event{{End: 19}, {End: 19}, {End: 2}}},
+			{ID: ID{ShortID: ShortID{Type: "впс", Name: "Велика Кісниця"}}},
+		},
+		AggregatedOther: []Group{
+			{ID: ID{ShortID: ShortID{Name: "Шершенці"}, Hint: "вночі"}, Events: []Event{{End: 24}}},
+			{ID: ID{ShortID: ShortID{Type: "ГОРВ"}}, Events: []Event{{End: 9}}},
+			{ID: ID{ShortID: ShortID{Name: "Олешки"}}},
+		},
+	}
+
+	timeline := NewTimeline(data)
+
+	if timeline.Hours[19] != 2 || timeline.Hours[2] != 1 || timeline.Hours[0] != 1 || timeline.Hours[9] != 1 {
+		t.Errorf("unexpected hours %v", timeline.Hours)
+	}
+
+	names := []string{}
+	for _, post := range timeline.Posts {
+		names = append(names, post.Name)
+	}
+
+	if len(names) != 4 || names[0] != "Кодима" || names[1] != "Велика Кісниця" || names[2] != "ГОРВ" || names[3] != "Шершенці вночі" {
+		t.Errorf("unexpected posts %v", names)
+	}
+
+	if timeline.Posts[0].Hours[19] != 2 || timeline.Posts[3].Hours[0] != 1 {
+		t.Errorf("unexpected post hours %v", timeline.Posts)
+	}
+}
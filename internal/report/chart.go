@@ -0,0 +1,122 @@
+package report
+
+import (
+	"fmt"
+	"go-doc-parser/internal/entity"
+	"html"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ChartKinds lists the values accepted by WriteChart
+var ChartKinds = []string{"timeline", "heatmap"}
+
+// the layout is shared with the charts drawn by report.js, so an exported chart looks like the one on the page
+const (
+	chartWidth    = 720
+	timelineH     = 200
+	timelineLeft  = 32
+	timelineRight = 8
+	timelineTop   = 12
+	timelineBelow = 24
+	heatmapLeft   = 160
+	heatmapRight  = 8
+	heatmapTop    = 20
+	heatmapRow    = 18
+	heatmapName   = 24 // runes of a post name before it is cut
+)
+
+// WriteChart draws the timeline as a standalone SVG, either the events of every hour as bars
+// or the posts by hour as a heatmap
+func WriteChart(w io.Writer, timeline entity.Timeline, kind string) error {
+	switch kind {
+	case "timeline":
+		_, err := io.WriteString(w, timelineSVG(timeline))
+		return err
+	case "heatmap":
+		_, err := io.WriteString(w, heatmapSVG(timeline))
+		return err
+	}
+
+	return fmt.Errorf("unknown chart %q", kind)
+}
+
+func svgOpen(out *strings.Builder, height float64) {
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %.1f" width="%d" height="%.1f" font-family="sans-serif" font-size="10">`, chartWidth, height, chartWidth, height)
+}
+
+// hourLabels marks every third hour above or below the columns
+func hourLabels(out *strings.Builder, left, slot, y float64) {
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%02d</text>`, left+(float64(hour)+0.5)*slot, y, hour)
+	}
+}
+
+func timelineSVG(timeline entity.Timeline) string {
+	out := strings.Builder{}
+
+	top := float64(timelineTop)
+	bottom := float64(timelineH - timelineBelow)
+	slot := float64(chartWidth-timelineLeft-timelineRight) / 24
+	most := max(1, slices.Max(timeline.Hours[:]))
+
+	svgOpen(&out, timelineH)
+
+	fmt.Fprintf(&out, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#888"/>`, timelineLeft, bottom, chartWidth-timelineRight, bottom)
+	fmt.Fprintf(&out, `<text x="%d" y="%.1f" text-anchor="end" fill="#666">%d</text>`, timelineLeft-4, top+4, most)
+	fmt.Fprintf(&out, `<text x="%d" y="%.1f" text-anchor="end" fill="#666">0</text>`, timelineLeft-4, bottom)
+
+	for hour, count := range timeline.Hours {
+		height := float64(count) / float64(most) * (bottom - top)
+
+		fmt.Fprintf(&out, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#2a7ab0"><title>%02d:00 — %d</title></rect>`,
+			timelineLeft+float64(hour)*slot+1, bottom-height, slot-2, height, hour, count)
+	}
+
+	hourLabels(&out, timelineLeft, slot, bottom+16)
+
+	out.WriteString(`</svg>`)
+
+	return out.String()
+}
+
+func heatmapSVG(timeline entity.Timeline) string {
+	out := strings.Builder{}
+
+	cell := float64(chartWidth-heatmapLeft-heatmapRight) / 24
+	most := 1
+
+	for _, post := range timeline.Posts {
+		most = max(most, slices.Max(post.Hours[:]))
+	}
+
+	svgOpen(&out, float64(heatmapTop+len(timeline.Posts)*heatmapRow+4))
+
+	hourLabels(&out, heatmapLeft, cell, heatmapTop-6)
+
+	for i, post := range timeline.Posts {
+		y := float64(heatmapTop + i*heatmapRow)
+
+		name := []rune(post.Name)
+		if len(name) > heatmapName {
+			name = append(name[:heatmapName-1], '…')
+		}
+
+		fmt.Fprintf(&out, `<text x="%d" y="%.1f" text-anchor="end">%s<title>%s</title></text>`, heatmapLeft-4, y+13, html.EscapeString(string(name)), html.EscapeString(post.Name))
+
+		for hour, count := range post.Hours {
+			fill := `fill="#f6f6f6"`
+			if count > 0 {
+				fill = fmt.Sprintf(`fill="#b02a4a" fill-opacity="%.2f"`, 0.15+0.85*float64(count)/float64(most))
+			}
+
+			fmt.Fprintf(&out, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" %s><title>%s, %02d:00 — %d</title></rect>`,
+				heatmapLeft+float64(hour)*cell, y, cell-1, heatmapRow-1, fill, html.EscapeString(post.Name), hour, count)
+		}
+	}
+
+	out.WriteString(`</svg>`)
+
+	return out.String()
+}
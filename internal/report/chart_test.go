@@ -0,0 +1,73 @@
+package report
+
+import (
+	"go-doc-parser/internal/entity"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var rect = regexp.MustCompile(`<rect x="([^"]*)" y="([^"]*)" width="([^"]*)" height="([^"]*)" ([^>]*)><title>([^<]*)</title>`)
+
+func TestWriteChart(t *testing.T) {
+	timeline := entity.Timeline{
+		Posts: []entity.TimelinePost{{Name: "Кодима"}, {Name: "ГОРВ <нічний>"}},
+	}
+
+	timeline.Posts[0].Hours[19] = 4
+	timeline.Posts[1].Hours[2] = 1
+	timeline.Hours[19], timeline.Hours[2] = 4, 2
+
+	out := strings.Builder{}
+
+	err := WriteChart(&out, timeline, "timeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bars := rect.FindAllStringSubmatch(out.String(), -1)
+	if len(bars) != 24 {
+		t.Fatalf("expected 24 bars, got %d", len(bars))
+	}
+
+	// the tallest bar spans the plot from 12 to 176, the others are scaled to it
+	for hour, expected := range map[int][2]string{19: {"12.0", "164.0"}, 2: {"94.0", "82.0"}, 0: {"176.0", "0.0"}} {
+		if bar := bars[hour]; bar[2] != expected[0] || bar[4] != expected[1] {
+			t.Errorf("bar %d: expected y %s and height %s, got %s and %s", hour, expected[0], expected[1], bar[2], bar[4])
+		}
+	}
+
+	out.Reset()
+
+	err = WriteChart(&out, timeline, "heatmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells := rect.FindAllStringSubmatch(out.String(), -1)
+	if len(cells) != 48 {
+		t.Fatalf("expected 24 cells for each of the 2 posts, got %d", len(cells))
+	}
+
+	tests := []struct {
+		cell  int
+		y     string
+		fill  string
+		title string
+	}{
+		{19, "20.0", `fill="#b02a4a" fill-opacity="1.00"`, "Кодима, 19:00 — 4"},
+		{24 + 2, "38.0", `fill="#b02a4a" fill-opacity="0.36"`, "ГОРВ &lt;нічний&gt;, 02:00 — 1"},
+		{0, "20.0", `fill="#f6f6f6"`, "Кодима, 00:00 — 0"},
+	}
+
+	for _, test := range tests {
+		cell := cells[test.cell]
+		if cell[2] != test.y || cell[5] != test.fill || cell[6] != test.title {
+			t.Errorf("cell %d: unexpected %q", test.cell, cell[0])
+		}
+	}
+
+	if err := WriteChart(&out, timeline, "pie"); err == nil {
+		t.Error("an unknown chart was drawn")
+	}
+}
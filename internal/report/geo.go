@@ -0,0 +1,155 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go-doc-parser/internal/entity"
+	"io"
+	"strings"
+)
+
+// placed is an event with the position it is shown at
+type placed struct {
+	page       entity.Page
+	id         entity.ID
+	supergroup string
+	event      entity.Event
+	point      entity.Point
+	source     string // "report" when the coordinates were written in the row, "post" for the location of the post
+}
+
+// place finds a position for every event: the first coordinates of its row,
+// otherwise the location of its post in the dictionary; events with neither are left out
+func place(data entity.Data) (out []placed) {
+	add := func(page entity.Page, group entity.Group, supergroup string) {
+		for _, event := range group.Events {
+			switch {
+			case len(event.Positions) > 0:
+				out = append(out, placed{page, group.ID, supergroup, event, event.Positions[0], "report"})
+			case group.Location != (entity.Point{}):
+				out = append(out, placed{page, group.ID, supergroup, event, group.Location, "post"})
+			}
+		}
+	}
+
+	for _, page := range data.Pages {
+		for _, groups := range page.SelectedSupergroups {
+			for _, group := range groups {
+				add(page, group, groups[0].Name)
+			}
+		}
+
+		for _, group := range page.OtherGroups {
+			add(page, group, "")
+		}
+	}
+
+	return
+}
+
+func (p placed) title() string {
+	return strings.Join(strings.Fields(strings.Join([]string{p.id.Type, p.id.Name, p.id.Hint}, " ")), " ")
+}
+
+type property struct {
+	name  string
+	value any
+}
+
+// properties describe an event the same way in both formats
+func (p placed) properties() []property {
+	return []property{
+		{"file", p.page.Filename},
+		{"row", p.event.Provenance.Row},
+		{"supergroup", p.supergroup},
+		{"type", p.id.Type},
+		{"name", p.id.Name},
+		{"hint", p.id.Hint},
+		{"start", p.event.Start},
+		{"end", p.event.End},
+		{"duration", p.event.Duration},
+		{"comment", p.event.Comment},
+		{"detained", p.event.Detained},
+		{"tags", strings.Join(p.event.Tags, ", ")},
+		{"location", p.source},
+	}
+}
+
+type geoJSONFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// writeGeoJSON writes the placed events as a FeatureCollection of points
+func writeGeoJSON(w io.Writer, data entity.Data) error {
+	features := []geoJSONFeature{}
+
+	for _, p := range place(data) {
+		feature := geoJSONFeature{Type: "Feature", Properties: map[string]any{"title": p.title()}}
+		feature.Geometry.Type = "Point"
+		feature.Geometry.Coordinates = [2]float64{p.point.Lon, p.point.Lat}
+
+		for _, property := range p.properties() {
+			feature.Properties[property.name] = property.value
+		}
+
+		features = append(features, feature)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]any{"type": "FeatureCollection", "features": features})
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPlacemark struct {
+	Name        string    `xml:"name"`
+	Description string    `xml:"description,omitempty"`
+	Data        []kmlData `xml:"ExtendedData>Data"`
+	Coordinates string    `xml:"Point>coordinates"`
+}
+
+type kmlDocument struct {
+	XMLName    xml.Name       `xml:"kml"`
+	Namespace  string         `xml:"xmlns,attr"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+// writeKML writes the placed events as placemarks
+func writeKML(w io.Writer, data entity.Data) error {
+	document := kmlDocument{Namespace: "http://www.opengis.net/kml/2.2"}
+
+	for _, p := range place(data) {
+		placemark := kmlPlacemark{
+			Name:        p.title(),
+			Description: p.event.Comment,
+			Coordinates: fmt.Sprintf("%.6f,%.6f", p.point.Lon, p.point.Lat),
+		}
+
+		for _, property := range p.properties() {
+			placemark.Data = append(placemark.Data, kmlData{property.name, fmt.Sprint(property.value)})
+		}
+
+		document.Placemarks = append(document.Placemarks, placemark)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(document)
+}
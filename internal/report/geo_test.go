@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"go-doc-parser/internal/entity"
+	"strings"
+	"testing"
+)
+
+const comment = `Затримано 2 осіб <"Кодима"> & автомобіль`
+
+func geoData() entity.Data {
+	post := entity.Group{ID: entity.ID{ShortID: entity.ShortID{Type: "ВПС", Name: "Кодима"}}, Location: entity.Point{Lat: 47.75, Lon: 29.5}}
+	post.Events = []entity.Event{
+		{Start: 18, End: 20, Duration: 120, Comment: comment, Detained: 2, Tags: []string{"затримання", "авто"},
+			Positions: []entity.Point{{Lat: 47.8, Lon: 29.45}, {Lat: 1, Lon: 2}}, Provenance: entity.Provenance{Row: 2}},
+		{Start: 21, End: 22, Comment: "без подій", Provenance: entity.Provenance{Row: 3}},
+	}
+
+	// no location in the dictionary and no coordinates in the row
+	other := entity.Group{ID: entity.ID{ShortID: entity.ShortID{Type: "ВПС", Name: "Ларга"}}}
+	other.Events = []entity.Event{{Start: 1, End: 2, Comment: "без подій", Provenance: entity.Provenance{Row: 4}}}
+
+	return entity.Data{Pages: []entity.Page{{
+		Filename:            "report.docx",
+		SelectedSupergroups: [][]entity.Group{{post}},
+		OtherGroups:         []entity.Group{other},
+	}}}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	out := strings.Builder{}
+
+	err := Write(&out, geoData(), "geojson")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection := struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates []float64
+			}
+			Properties map[string]any
+		}
+	}{}
+
+	err = json.Unmarshal([]byte(out.String()), &collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Fatalf("expected a FeatureCollection of the 2 placed events, got %s of %d", collection.Type, len(collection.Features))
+	}
+
+	// GeoJSON puts the longitude first
+	for i, expected := range []struct {
+		lon, lat float64
+		location string
+	}{{29.45, 47.8, "report"}, {29.5, 47.75, "post"}} {
+		feature := collection.Features[i]
+
+		if feature.Geometry.Type != "Point" || len(feature.Geometry.Coordinates) != 2 ||
+			feature.Geometry.Coordinates[0] != expected.lon || feature.Geometry.Coordinates[1] != expected.lat {
+			t.Errorf("feature %d: expected a point at [%v, %v], got %s %v", i, expected.lon, expected.lat, feature.Geometry.Type, feature.Geometry.Coordinates)
+		}
+
+		if feature.Properties["location"] != expected.location {
+			t.Errorf("feature %d: expected the %s location, got %v", i, expected.location, feature.Properties["location"])
+		}
+	}
+
+	for name, expected := range map[string]any{
+		"title":      "ВПС Кодима",
+		"file":       "report.docx",
+		"row":        2.0,
+		"supergroup": "Кодима",
+		"type":       "ВПС",
+		"name":       "Кодима",
+		"hint":       "",
+		"start":      18.0,
+		"end":        20.0,
+		"duration":   120.0,
+		"comment":    comment,
+		"detained":   2.0,
+		"tags":       "затримання, авто",
+	} {
+		if got := collection.Features[0].Properties[name]; got != expected {
+			t.Errorf("property %s: expected %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	out := strings.Builder{}
+
+	err := Write(&out, geoData(), "kml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Error("expected the XML header")
+	}
+
+	if strings.Contains(out.String(), comment) || !strings.Contains(out.String(), "&lt;&#34;Кодима&#34;&gt; &amp; автомобіль") {
+		t.Errorf("expected the comment to be escaped, got\n%s", out.String())
+	}
+
+	document := kmlDocument{}
+
+	err = xml.Unmarshal([]byte(out.String()), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(document.Placemarks) != 2 {
+		t.Fatalf("expected 2 placemarks, got %d", len(document.Placemarks))
+	}
+
+	placemark := document.Placemarks[0]
+	if placemark.Name != "ВПС Кодима" || placemark.Description != comment || placemark.Coordinates != "29.450000,47.800000" {
+		t.Errorf("unexpected placemark %+v", placemark)
+	}
+
+	data := map[string]string{}
+	for _, d := range placemark.Data {
+		data[d.Name] = d.Value
+	}
+
+	if data["comment"] != comment || data["row"] != "2" || data["location"] != "report" {
+		t.Errorf("unexpected extended data %v", data)
+	}
+
+	if got := document.Placemarks[1].Coordinates; got != "29.500000,47.750000" {
+		t.Errorf("expected the post location, got %s", got)
+	}
+}
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-doc-parser/internal/assets"
+	"go-doc-parser/internal/entity"
+	"html/template"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+)
+
+// Formats lists the values accepted by Write
+var Formats = []string{"text", "json", "csv", "html", "geojson", "kml"}
+
+// Write renders the processed data in one of the Formats;
+// html is written as a standalone page with every asset inlined, geojson and kml hold the events with a position
+func Write(w io.Writer, data entity.Data, format string) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, data.Summary)
+		return err
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case "csv":
+		return writeCSV(w, data)
+	case "html":
+		return HTML(w, data, true)
+	case "geojson":
+		return writeGeoJSON(w, data)
+	case "kml":
+		return writeKML(w, data)
+	}
+
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// ContentTypes are the media types of the Formats
+var ContentTypes = map[string]string{
+	"text":    "text/plain; charset=utf-8",
+	"json":    "application/json",
+	"csv":     "text/csv; charset=utf-8",
+	"html":    "text/html; charset=utf-8",
+	"geojson": "application/geo+json",
+	"kml":     "application/vnd.google-earth.kml+xml",
+}
+
+type script struct {
+	Name   string
+	Source template.JS
+}
+
+type page struct {
+	Data    entity.Data
+	Inline  bool
+	Style   template.CSS
+	Scripts []script
+	Emblem  template.URL
+}
+
+// scripts are loaded by the page in this order
+var scripts = []string{"van.js", "report.js"}
+
+// HTML renders the report page; inline embeds the scripts, styles and images
+// so the page can be opened from disk, otherwise they are loaded from /static
+func HTML(w io.Writer, data entity.Data, inline bool) error {
+	tpl, err := template.ParseFS(assets.Files, assets.Template)
+	if err != nil {
+		return err
+	}
+
+	p := page{
+		Data:   data,
+		Inline: inline,
+		Emblem: "/static/emblem.svg",
+	}
+
+	static := assets.Static()
+
+	for _, name := range scripts {
+		s := script{Name: name}
+
+		if inline {
+			source, err := fs.ReadFile(static, name)
+			if err != nil {
+				return err
+			}
+
+			s.Source = template.JS(source)
+		}
+
+		p.Scripts = append(p.Scripts, s)
+	}
+
+	if inline {
+		style, err := fs.ReadFile(static, "report.css")
+		if err != nil {
+			return err
+		}
+
+		emblem, err := fs.ReadFile(static, "emblem.svg")
+		if err != nil {
+			return err
+		}
+
+		p.Style = template.CSS(style)
+		p.Emblem = template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(emblem))
+	}
+
+	return tpl.Execute(w, p)
+}
+
+// writeCSV lists every group of every page followed by the totals for all documents
+func writeCSV(w io.Writer, data entity.Data) error {
+	out := csv.NewWriter(w)
+
+	row := func(filename, supergroup string, group entity.Group) {
+		comments := 0
+		for _, event := range group.Events {
+			if len(event.Comment) > 0 {
+				comments++
+			}
+		}
+
+		out.Write([]string{
+			filename,
+			supergroup,
+			group.Type,
+			group.Name,
+			group.Hint,
+			strconv.Itoa(len(group.Events)),
+			strconv.Itoa(comments),
+		})
+	}
+
+	out.Write([]string{"file", "supergroup", "type", "name", "hint", "events", "comments"})
+
+	for _, page := range data.Pages {
+		for _, groups := range page.SelectedSupergroups {
+			for _, group := range groups {
+				row(page.Filename, groups[0].Name, group)
+			}
+		}
+
+		for _, group := range page.OtherGroups {
+			row(page.Filename, "", group)
+		}
+	}
+
+	for _, group := range data.AggregatedSelected {
+		row("", group.Name, entity.Group{Events: group.Events})
+	}
+
+	for _, group := range data.AggregatedOther {
+		row("", "", group)
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
+
+// PivotFormats lists the values accepted by WritePivot
+var PivotFormats = []string{"text", "json", "csv"}
+
+// WritePivot renders a pivot in one of the PivotFormats, text is a tab separated table
+func WritePivot(w io.Writer, pivot entity.Pivot, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(pivot)
+	case "text", "csv":
+		out := csv.NewWriter(w)
+		if format == "text" {
+			out.Comma = '\t'
+		}
+
+		out.Write(append(slices.Clone(pivot.By), "count", "duration", "first", "last"))
+
+		for _, row := range pivot.Rows {
+			out.Write(append(slices.Clone(row.Values),
+				strconv.Itoa(row.Count),
+				strconv.FormatUint(row.Duration, 10),
+				strconv.FormatUint(row.First, 10),
+				strconv.FormatUint(row.Last, 10),
+			))
+		}
+
+		out.Flush()
+
+		return out.Error()
+	}
+
+	return fmt.Errorf("unknown pivot format %q", format)
+}
@@ -0,0 +1,243 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/processor"
+	"go-doc-parser/internal/report"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watcher polls directories for reports modified today and keeps the summary and
+// the HTML report in the output directory up to date
+type Watcher struct {
+	Dirs      []string
+	Output    string
+	Interval  time.Duration // how often the directories are scanned
+	Debounce  time.Duration // how long a file must stay unchanged before it is parsed
+	Aggregate func(files []processor.File) entity.Data
+	Options   processor.Options
+	Cutoff    uint64
+
+	pending map[string]observation
+	parsed  map[string]parsedFile
+	day     string
+}
+
+type observation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+type parsedFile struct {
+	size    int64
+	modTime time.Time
+	file    processor.File
+}
+
+// Run scans until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	w.pending = map[string]observation{}
+	w.parsed = map[string]parsedFile{}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if w.scan(time.Now()) {
+			err := w.write()
+			if err != nil {
+				log.Println("failed to write the report:", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan updates the set of parsed files and reports whether it changed
+func (w *Watcher) scan(now time.Time) (changed bool) {
+	day := now.Format(time.DateOnly)
+	if day != w.day {
+		// a new day starts with an empty set
+		w.day = day
+		changed = true
+	}
+
+	seen := map[string]bool{}
+
+	for _, dir := range w.Dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Println("failed to scan:", err)
+				return nil
+			}
+
+			if d.IsDir() || !processor.IsDocument(d.Name()) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			if info.ModTime().Format(time.DateOnly) != day {
+				return nil
+			}
+
+			seen[path] = true
+
+			if w.observe(path, info, now) {
+				changed = true
+			}
+
+			return nil
+		})
+		if err != nil {
+			log.Println("failed to scan:", err)
+		}
+	}
+
+	for path := range w.parsed {
+		if !seen[path] {
+			log.Println("removed:", path)
+			delete(w.parsed, path)
+			changed = true
+		}
+	}
+
+	for path := range w.pending {
+		if !seen[path] {
+			delete(w.pending, path)
+		}
+	}
+
+	return
+}
+
+// observe parses the file once it has stopped changing for the debounce period
+func (w *Watcher) observe(path string, info fs.FileInfo, now time.Time) bool {
+	size, modTime := info.Size(), info.ModTime()
+
+	parsed, ok := w.parsed[path]
+	if ok && parsed.size == size && parsed.modTime.Equal(modTime) {
+		return false
+	}
+
+	pending, ok := w.pending[path]
+	if !ok || pending.size != size || !pending.modTime.Equal(modTime) {
+		// still being written
+		w.pending[path] = observation{size: size, modTime: modTime, since: now}
+		return false
+	}
+
+	if now.Sub(pending.since) < w.Debounce {
+		return false
+	}
+
+	delete(w.pending, path)
+
+	source := processor.FileSource(path)
+	source.Name = w.relative(path)
+	source.Folder = filepath.ToSlash(filepath.Dir(source.Name))
+
+	file := processor.Parse(source, w.Options)
+	if file.Err != nil {
+		log.Printf("failed: %s: %s", path, file.Err)
+	} else {
+		log.Printf("processed: %s (%d records)", path, len(file.Records))
+	}
+
+	w.parsed[path] = parsedFile{size: size, modTime: modTime, file: file}
+
+	return true
+}
+
+// relative names a file after its watched directory, so pages from different folders stay apart
+func (w *Watcher) relative(path string) string {
+	for _, dir := range w.Dirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(filepath.Join(filepath.Base(dir), rel))
+		}
+	}
+
+	return filepath.Base(path)
+}
+
+func (w *Watcher) write() error {
+	paths := []string{}
+	for path := range w.parsed {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	files := []processor.File{}
+	for _, path := range paths {
+		files = append(files, w.parsed[path].file)
+	}
+
+	data := processor.Cutoff(w.Aggregate(files), w.Cutoff)
+
+	err := os.MkdirAll(w.Output, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for name, format := range map[string]string{"summary.txt": "text", "report.html": "html"} {
+		buffer := bytes.Buffer{}
+
+		err := report.Write(&buffer, data, format)
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(filepath.Join(w.Output, name), buffer.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("report updated: %d files", len(files))
+
+	return nil
+}
+
+// writeFile replaces the file atomically, so readers never see a half written report
+func writeFile(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Chmod(0o644)
+	}
+	if err == nil {
+		err = temp.Close()
+	} else {
+		temp.Close()
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
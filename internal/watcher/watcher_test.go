@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// write puts a report in the directory as if it was saved at the time
+func write(t *testing.T, path, content string, at time.Time) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err == nil {
+		err = os.Chtimes(path, at, at)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newWatcher(dir string) *Watcher {
+	return &Watcher{
+		Dirs:     []string{dir},
+		Debounce: 10 * time.Second,
+		pending:  map[string]observation{},
+		parsed:   map[string]parsedFile{},
+	}
+}
+
+// expect scans at the time and checks whether the set changed and which files are parsed
+func expect(t *testing.T, w *Watcher, now time.Time, changed bool, parsed ...string) {
+	t.Helper()
+
+	if got := w.scan(now); got != changed {
+		t.Errorf("%s: expected changed %v, got %v", now.Format(time.TimeOnly), changed, got)
+	}
+
+	if len(w.parsed) != len(parsed) {
+		t.Errorf("%s: expected %d parsed files, got %d", now.Format(time.TimeOnly), len(parsed), len(w.parsed))
+	}
+
+	for _, path := range parsed {
+		if _, ok := w.parsed[path]; !ok {
+			t.Errorf("%s: expected %s to be parsed", now.Format(time.TimeOnly), path)
+		}
+	}
+}
+
+var morning = time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+
+func TestDebounce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	w := newWatcher(dir)
+
+	expect(t, w, morning, true) // the first scan starts the day
+
+	write(t, path, "Час;Підрозділ\n", morning)
+	expect(t, w, morning.Add(time.Second), false)
+	expect(t, w, morning.Add(5*time.Second), false)
+	expect(t, w, morning.Add(11*time.Second), true, path)
+	expect(t, w, morning.Add(20*time.Second), false, path)
+
+	// a file being written again waits for the debounce period once more
+	write(t, path, "Час;Підрозділ\n19:10;впс Кодима\n", morning.Add(25*time.Second))
+	expect(t, w, morning.Add(30*time.Second), false, path)
+	expect(t, w, morning.Add(35*time.Second), false, path)
+	expect(t, w, morning.Add(41*time.Second), true, path)
+
+	if size := w.parsed[path].size; size != int64(len("Час;Підрозділ\n19:10;впс Кодима\n")) {
+		t.Errorf("expected the second version to be parsed, got %d bytes", size)
+	}
+}
+
+func TestRemoved(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.csv")
+	removed := filepath.Join(dir, "removed.csv")
+	w := newWatcher(dir)
+
+	write(t, kept, "Час\n", morning)
+	write(t, removed, "Час\n", morning)
+	expect(t, w, morning, true)
+	expect(t, w, morning.Add(11*time.Second), true, kept, removed)
+
+	err := os.Remove(removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, w, morning.Add(20*time.Second), true, kept)
+	expect(t, w, morning.Add(30*time.Second), false, kept)
+
+	// a file removed while it is still pending is forgotten as well
+	write(t, removed, "Час\n", morning.Add(35*time.Second))
+	expect(t, w, morning.Add(40*time.Second), false, kept)
+
+	err = os.Remove(removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, w, morning.Add(45*time.Second), false, kept)
+
+	if len(w.pending) != 0 {
+		t.Errorf("expected nothing pending, got %v", w.pending)
+	}
+}
+
+func TestDayRollover(t *testing.T) {
+	dir := t.TempDir()
+	yesterday := filepath.Join(dir, "yesterday.csv")
+	today := filepath.Join(dir, "today.csv")
+	w := newWatcher(dir)
+
+	write(t, yesterday, "Час\n", morning)
+	expect(t, w, morning, true)
+	expect(t, w, morning.Add(11*time.Second), true, yesterday)
+
+	// the reports of the day before are dropped at midnight, the new ones are picked up
+	midnight := time.Date(2026, 3, 11, 0, 0, 5, 0, time.Local)
+	write(t, today, "Час\n", midnight)
+
+	expect(t, w, midnight, true)
+	expect(t, w, midnight.Add(11*time.Second), true, today)
+	expect(t, w, midnight.Add(20*time.Second), false, today)
+}
+
+func TestAtomicWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	w := newWatcher(dir)
+
+	write(t, path, "Час\n", morning)
+	expect(t, w, morning, true)
+	expect(t, w, morning.Add(11*time.Second), true, path)
+
+	// editors save to a hidden temporary file and rename it over the report
+	temp := filepath.Join(dir, ".report.csv-123")
+	write(t, temp, "Час\n19:10\n", morning.Add(15*time.Second))
+	expect(t, w, morning.Add(16*time.Second), false, path)
+
+	if len(w.pending) != 0 {
+		t.Errorf("the temporary file should not be watched, got %v", w.pending)
+	}
+
+	err := os.Rename(temp, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, w, morning.Add(20*time.Second), false, path)
+	expect(t, w, morning.Add(31*time.Second), true, path)
+
+	if size := w.parsed[path].size; size != int64(len("Час\n19:10\n")) {
+		t.Errorf("expected the renamed version to be parsed, got %d bytes", size)
+	}
+}
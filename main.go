@@ -3,20 +3,32 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"go-doc-parser/internal/document"
 	"go-doc-parser/internal/entity"
+	"go-doc-parser/internal/extract"
 	"go-doc-parser/internal/handler"
+	"go-doc-parser/internal/jobs"
+	"go-doc-parser/internal/parser"
 	"go-doc-parser/internal/processor"
 	"net/http"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func main() {
-	// dictionary sets up the right order for the names
-	dictionary := [][]entity.ID{}
-
-	data := os.Getenv("DATA")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "process":
+			os.Exit(runProcess(os.Args[2:]))
+		case "watch":
+			os.Exit(runWatch(os.Args[2:]))
+		}
+	}
 
-	err := json.Unmarshal([]byte(data), &dictionary)
+	dictionary, locations, err := loadDictionary([]byte(os.Getenv("DATA")))
 	if err != nil {
 		fmt.Println("failed to unmarshal the data:", err)
 		return
@@ -27,12 +39,176 @@ func main() {
 		port = "4000"
 	}
 
-	process := processor.NewProcessor(dictionary)
+	limits := processor.Limits{
+		MaxBodySize:         envLimit("MAX_BODY_SIZE", processor.DefaultLimits.MaxBodySize),
+		MaxEntries:          envLimit("MAX_ENTRIES", processor.DefaultLimits.MaxEntries),
+		MaxTotalSize:        envLimit("MAX_TOTAL_SIZE", processor.DefaultLimits.MaxTotalSize),
+		MaxEntrySize:        envLimit("MAX_ENTRY_SIZE", processor.DefaultLimits.MaxEntrySize),
+		MaxCompressionRatio: envLimit("MAX_COMPRESSION_RATIO", processor.DefaultLimits.MaxCompressionRatio),
+		MaxDepth:            envLimit("MAX_DEPTH", processor.DefaultLimits.MaxDepth),
+		MaxXMLSize:          envLimit("MAX_XML_SIZE", processor.DefaultLimits.MaxXMLSize),
+		MaxXMLDepth:         envLimit("MAX_XML_DEPTH", processor.DefaultLimits.MaxXMLDepth),
+	}
+
+	keyring, err := readKeyring(os.Getenv("KEYRING"))
+	if err != nil {
+		fmt.Println("failed to read the keyring:", err)
+		return
+	}
+
+	revisions, err := document.ParseRevisions(os.Getenv("REVISIONS"))
+	if err != nil {
+		fmt.Println("failed to read the revisions mode:", err)
+		return
+	}
+
+	noFindings, err := parser.LoadNoFindings(os.Getenv("NO_FINDINGS"))
+	if err != nil {
+		fmt.Println("failed to read the no findings phrases:", err)
+		return
+	}
+
+	rules, err := processor.LoadRules(os.Getenv("RULES"))
+	if err != nil {
+		fmt.Println("failed to read the rules:", err)
+		return
+	}
+
+	summary, err := processor.LoadSummaryTemplate(os.Getenv("SUMMARY_TEMPLATE"))
+	if err != nil {
+		fmt.Println("failed to read the summary template:", err)
+		return
+	}
+
+	gazetteer, err := extract.LoadGazetteer(os.Getenv("GAZETTEER"))
+	if err != nil {
+		fmt.Println("failed to read the gazetteer:", err)
+		return
+	}
 
-	http.ListenAndServe(
-		"0.0.0.0:"+port,
-		http.HandlerFunc(
-			handler.Handler(process),
-		),
+	basemap, err := processor.LoadBasemap(os.Getenv("BASEMAP"))
+	if err != nil {
+		fmt.Println("failed to read the basemap:", err)
+		return
+	}
+
+	process := processor.NewProcessor(dictionary, processor.Options{
+		Workers:    envInt("PARSE_WORKERS", runtime.NumCPU()),
+		Limits:     limits,
+		Passwords:  keyring,
+		Revisions:  revisions,
+		NoFindings: noFindings,
+		Rules:      rules,
+		Summary:    summary,
+		Gazetteer:  gazetteer,
+		Basemap:    basemap,
+		Locations:  locations,
+	})
+
+	manager := jobs.NewManager(
+		process,
+		envInt("JOB_WORKERS", 2),
+		envInt("JOB_QUEUE", 64),
+		envDuration("JOB_TTL", time.Hour),
 	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", handler.Static())
+	mux.HandleFunc("POST /jobs", handler.SubmitJob(manager, limits))
+	mux.HandleFunc("GET /jobs/{id}", handler.JobStatus(manager))
+	mux.HandleFunc("GET /jobs/{id}/events", handler.JobEvents(manager))
+	mux.HandleFunc("GET /jobs/{id}/report", handler.JobReport(manager))
+	mux.HandleFunc("GET /jobs/{id}/pivot", handler.JobPivot(manager))
+	mux.HandleFunc("GET /jobs/{id}/explain", handler.JobExplain(manager))
+	mux.HandleFunc("GET /jobs/{id}/export", handler.JobExport(manager))
+	mux.HandleFunc("GET /jobs/{id}/chart", handler.JobChart(manager))
+	mux.HandleFunc("/", handler.Handler(process, limits))
+
+	http.ListenAndServe("0.0.0.0:"+port, mux)
+}
+
+// loadDictionary splits the dictionary entries into the IDs and the locations some of them carry
+func loadDictionary(data []byte) ([][]entity.ID, map[entity.ShortID]entity.Point, error) {
+	entries := [][]struct {
+		entity.ID
+		Location entity.Point
+	}{}
+
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// dictionary sets up the right order for the names
+	dictionary := [][]entity.ID{}
+	locations := map[entity.ShortID]entity.Point{}
+
+	for _, group := range entries {
+		ids := []entity.ID{}
+
+		for _, entry := range group {
+			ids = append(ids, entry.ID)
+
+			if entry.Location != (entity.Point{}) {
+				locations[entry.ShortID] = entry.Location
+			}
+		}
+
+		dictionary = append(dictionary, ids)
+	}
+
+	return dictionary, locations, nil
+}
+
+// readKeyring reads the passwords tried on every encrypted document, one per line, an empty path means none
+func readKeyring(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	passwords := []string{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+
+	return passwords, nil
+}
+
+// envInt reads a positive number from the environment, falling back to the default
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 1 {
+		return fallback
+	}
+
+	return value
+}
+
+// envLimit reads one of the processor.Limits from the environment, zero disables it like in the struct
+func envLimit[T int | int64](name string, fallback T) T {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+
+	return T(value)
+}
+
+// envDuration reads a duration such as "30m" from the environment, falling back to the default
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
 }